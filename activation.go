@@ -0,0 +1,201 @@
+package nn
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+var (
+	errUnknownActivation = errors.New("unknown activation")
+)
+
+// Activation is the function applied to the weighted input of a layer
+type Activation struct {
+	Name string
+
+	// Alpha is the parameter of activations that take one, such as ELU
+	Alpha float64 `json:",omitempty"`
+}
+
+var (
+	// Sigmoid squashes values into the range 0 to 1, and is used by every layer unless told otherwise
+	Sigmoid = Activation{Name: "sigmoid"}
+
+	// Linear leaves values unchanged, so outputs can learn regression targets outside the range 0 to 1
+	Linear = Activation{Name: "linear"}
+
+	// PReLU leaves positive values unchanged and multiplies negative ones by a slope, which each layer using it learns
+	// during training starting from 0.25
+	PReLU = Activation{Name: "prelu"}
+
+	// ELU leaves positive values unchanged and smoothly approaches -1 for negative ones. NewELU sets how far negative
+	// values can go.
+	ELU = NewELU(1)
+
+	// Softplus is a smooth version of the ReLU, log(1+e^x), which never stops passing gradients
+	Softplus = Activation{Name: "softplus"}
+
+	// Softmax turns a layer's values into probabilities that sum to 1, for classifier outputs with one value per
+	// class. It is best trained with CategoricalCrossEntropy.
+	Softmax = Activation{Name: "softmax"}
+)
+
+// NewELU Creates an ELU activation whose negative values approach -alpha
+func NewELU(alpha float64) Activation {
+	return Activation{Name: "elu", Alpha: alpha}
+}
+
+// defaultPReLUSlope is the slope PReLU layers start with
+const defaultPReLUSlope = 0.25
+
+// valid reports whether the activation is one the package knows about
+func (a Activation) valid() bool {
+	switch a {
+	case Sigmoid, Linear, PReLU, Softplus, Softmax:
+		return true
+	}
+
+	return a.Name == ELU.Name && a.Alpha > 0
+}
+
+// apply evaluates the activation for a layer's weighted input
+func (a Activation) apply(z mat.Matrix) mat.Matrix {
+	switch a.Name {
+	case Linear.Name:
+		return z
+	case ELU.Name:
+		return fun(func(_, _ int, v float64) float64 {
+			if v > 0 {
+				return v
+			}
+
+			return a.Alpha * math.Expm1(v)
+		}, z)
+	case Softplus.Name:
+		return fun(softplus, z)
+	case Softmax.Name:
+		r, _ := z.Dims()
+		return mat.NewDense(r, 1, softmax(mat.Col(nil, 0, z)))
+	}
+
+	return fun(sigmoid, z)
+}
+
+// backward takes the gradient of the cost with respect to the activation and returns it with respect to the
+// weighted input
+func (a Activation) backward(z, activation, grad mat.Matrix) mat.Matrix {
+	switch a.Name {
+	case Linear.Name:
+		return grad
+	case ELU.Name:
+		return fun(func(i, j int, v float64) float64 {
+			if z.At(i, j) > 0 {
+				return v
+			}
+
+			return v * a.Alpha * math.Exp(z.At(i, j))
+		}, grad)
+	case Softplus.Name:
+		return mul(grad, fun(sigmoid, z))
+	case Softmax.Name:
+		// Every output depends on every input, so the gradient is multiplied by the Jacobian s_i(δ_ij - s_j)
+		s := a.apply(z)
+		r, _ := s.Dims()
+		g := 0.0
+
+		for i := 0; i < r; i++ {
+			g += grad.At(i, 0) * s.At(i, 0)
+		}
+
+		return fun(func(i, _ int, v float64) float64 {
+			return s.At(i, 0) * (v - g)
+		}, grad)
+	}
+
+	return mul(grad, fun(dSigmoid, z))
+}
+
+// activate applies the layer's activation to its weighted input
+func (l layer) activate(z mat.Matrix) mat.Matrix {
+	if l.activation != PReLU {
+		return l.activation.apply(z)
+	}
+
+	slope := l.slope.At(0, 0)
+
+	return fun(func(_, _ int, v float64) float64 {
+		if v < 0 {
+			return slope * v
+		}
+
+		return v
+	}, z)
+}
+
+// deactivate takes the gradient of the cost with respect to the layer's activation and returns it with respect to
+// the weighted input, along with the gradient of the PReLU slope if the layer has one
+func (l layer) deactivate(z, activation, grad mat.Matrix) (delta, slope mat.Matrix) {
+	if l.activation != PReLU {
+		return l.activation.backward(z, activation, grad), nil
+	}
+
+	s, total := l.slope.At(0, 0), 0.0
+
+	delta = fun(func(i, j int, v float64) float64 {
+		if z.At(i, j) < 0 {
+			total += v * z.At(i, j)
+			return v * s
+		}
+
+		return v
+	}, grad)
+
+	return delta, mat.NewDense(1, 1, []float64{total})
+}
+
+// setActivation changes the layer's activation, giving it a slope to learn if it is becoming a PReLU layer, and
+// dropping its temperature unless it stays a sigmoid or softmax layer, which are the only ones calibrated
+func (l *layer) setActivation(a Activation) {
+	switch {
+	case a == PReLU && l.slope == nil && l.custom == nil:
+		l.slope = mat.NewDense(1, 1, []float64{defaultPReLUSlope})
+	case a != PReLU:
+		l.slope = nil
+	}
+
+	if a != Sigmoid && a != Softmax {
+		l.temperature = 0
+	}
+
+	l.activation = a
+}
+
+// SetActivation changes the activation of a dense layer, numbered from 0
+func (n *Network) SetActivation(layer int, a Activation) error {
+	if layer < 0 || layer >= n.h || n.layers[layer].custom != nil || !a.valid() {
+		return errUnknownActivation
+	}
+
+	n.layers[layer].setActivation(a)
+	return nil
+}
+
+// SetOutputActivation changes the activation of every dense layer that is an output of the network, independently of
+// the activation of the hidden layers. Linear outputs suit regression, Sigmoid outputs independent labels and Softmax
+// outputs a single class out of several. It returns an error if the activation is unknown, like SetActivation.
+func (n *Network) SetOutputActivation(a Activation) error {
+	if !a.valid() {
+		return errUnknownActivation
+	}
+
+	for _, o := range n.outputs {
+		if o < len(n.inputs) {
+			continue
+		}
+
+		n.layers[o-len(n.inputs)].setActivation(a)
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package nn
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/rand"
+)
+
+// CostGradient returns the gradient of the cost of a single sample with respect to each of the inputs, which points
+// in the direction the input would have to move to make the network's prediction worse
+func (n Network) CostGradient(inputData, expectedData []float64) []float64 {
+	if err := n.checkSample(inputData, expectedData); err != nil {
+		panic(err)
+	}
+
+	zs, activations := n.forward(split(inputData, n.inputs))
+	expected := split(expectedData, n.outputSizes())
+	outputGrads := make([]mat.Matrix, len(n.outputs))
+
+	for i, a := range n.outputs {
+		outputGrads[i] = scl(n.heads[i].weight, n.heads[i].loss.grad(activations[a], expected[i]))
+	}
+
+	_, layerErrors := n.backward(zs, activations, nil, outputGrads)
+
+	return n.inputValues(layerErrors)
+}
+
+// FGSM returns an adversarial version of a sample made with the fast gradient sign method, moving every input by
+// epsilon in whichever direction increases the cost. Robust classifiers should give much the same prediction for
+// both.
+func (n Network) FGSM(input, expected []float64, epsilon float64) []float64 {
+	grad := n.CostGradient(input, expected)
+	res := make([]float64, len(input))
+
+	for i := range input {
+		res[i] = input[i] + epsilon*sign(grad[i])
+	}
+
+	return res
+}
+
+// IterativeFGSM returns an adversarial version of a sample made by taking steps fast gradient sign steps of size step,
+// keeping every input within epsilon of its original value. It finds stronger attacks than FGSM with the same
+// epsilon, at the cost of a gradient for every step.
+func (n Network) IterativeFGSM(input, expected []float64, epsilon, step float64, steps int) []float64 {
+	res := append([]float64(nil), input...)
+
+	for s := 0; s < steps; s++ {
+		grad := n.CostGradient(res, expected)
+
+		for i := range res {
+			res[i] += step * sign(grad[i])
+			res[i] = math.Max(input[i]-epsilon, math.Min(input[i]+epsilon, res[i]))
+		}
+	}
+
+	return res
+}
+
+// sign returns -1, 0 or 1 depending on the sign of x
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// adversarial decides whether the next sample trained on should also get an adversarial copy
+func (opts TrainOptions) adversarial() bool {
+	if opts.AdversarialEpsilon == 0 {
+		return false
+	}
+
+	return opts.AdversarialFraction == 0 || rand.Float64() < opts.AdversarialFraction
+}
+
+// adversarialSample makes an adversarial copy of a sample for training, as configured by opts
+func (n Network) adversarialSample(input, expected []float64, opts TrainOptions) []float64 {
+	if opts.AdversarialSteps > 1 {
+		step := opts.AdversarialEpsilon / float64(opts.AdversarialSteps)
+		return n.IterativeFGSM(input, expected, opts.AdversarialEpsilon, step, opts.AdversarialSteps)
+	}
+
+	return n.FGSM(input, expected, opts.AdversarialEpsilon)
+}
@@ -0,0 +1,159 @@
+package nn
+
+import (
+	"encoding/json"
+	"errors"
+	"gonum.org/v1/gonum/mat"
+	"io/ioutil"
+	"math"
+	"math/rand"
+)
+
+var (
+	errCustomArchitecture = errors.New("architectures with user defined layers can't be saved")
+)
+
+// architectureProjection marks projection skips in architecture files, which have no weights to point to
+const architectureProjection = "projection"
+
+// Initializer returns a random starting value for a parameter of a layer with fanIn inputs and fanOut outputs
+type Initializer func(fanIn, fanOut int) float64
+
+var (
+	// UniformInit draws values uniformly between -1 and 1, like NewNetwork does
+	UniformInit Initializer = func(_, _ int) float64 {
+		return lerp(rand.Float64(), 0, 1, -1, 1)
+	}
+
+	// XavierInit draws values uniformly within ±sqrt(6/(fanIn+fanOut)), which suits sigmoid layers
+	XavierInit Initializer = func(fanIn, fanOut int) float64 {
+		limit := math.Sqrt(6 / float64(fanIn+fanOut))
+		return lerp(rand.Float64(), 0, 1, -limit, limit)
+	}
+
+	// HeInit draws values from a normal distribution with a standard deviation of sqrt(2/fanIn)
+	HeInit Initializer = func(fanIn, _ int) float64 {
+		return rand.NormFloat64() * math.Sqrt(2/float64(fanIn))
+	}
+)
+
+// Reinitialize gives the network fresh random parameters drawn from init, or UniformInit when it is nil, keeping its
+// architecture. Every weight and bias of the dense layers is redrawn, and the parameters of user defined layers are
+// redrawn in place treating each as a matrix with one row per output. Projection skips start at zero again, and PReLU
+// slopes at 0.25.
+func (n *Network) Reinitialize(init Initializer) {
+	if init == nil {
+		init = UniformInit
+	}
+
+	draw := func(r, c, fanIn int) *mat.Dense {
+		data := make([]float64, r*c)
+
+		for i := range data {
+			data[i] = init(fanIn, r)
+		}
+
+		return mat.NewDense(r, c, data)
+	}
+
+	for i := range n.layers {
+		l := &n.layers[i]
+
+		if l.custom != nil {
+			for _, p := range l.custom.Params() {
+				r, c := p.Dims()
+				p.Copy(draw(r, c, c))
+			}
+
+			continue
+		}
+
+		r, c := l.size(), n.size(l.from)
+
+		if l.weights != nil {
+			l.weights = draw(r, c, c)
+		}
+
+		if l.biases != nil {
+			l.biases = draw(r, 1, c)
+		}
+
+		for j, s := range l.skips {
+			if s.weights != nil {
+				sr, sc := s.weights.Dims()
+				l.skips[j].weights = mat.NewDense(sr, sc, nil)
+			}
+		}
+
+		if l.slope != nil {
+			l.slope = mat.NewDense(1, 1, []float64{defaultPReLUSlope})
+		}
+	}
+}
+
+// SaveArchitecture saves the structure and settings of the network as JSON, without any of its weights, so that the
+// same architecture can be trained again from scratch with LoadArchitecture. Networks with user defined layers can't
+// be saved this way, as those layers store their settings along with their parameters.
+func (n Network) SaveArchitecture(filename string) error {
+	opts := n.options()
+
+	for i, l := range n.layers {
+		if l.custom != nil {
+			return errCustomArchitecture
+		}
+
+		opts.WPaths[i], opts.BPaths[i] = "", ""
+	}
+
+	for i := range opts.Skips {
+		if opts.Skips[i].Path != "" {
+			opts.Skips[i].Path = architectureProjection
+		}
+	}
+
+	data, err := json.MarshalIndent(opts, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, data, 0o644)
+}
+
+// LoadArchitecture builds a network from an architecture saved by SaveArchitecture, with random weights drawn by
+// init, or UniformInit when it is nil
+func LoadArchitecture(filename string, init Initializer) (Network, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return Network{}, err
+	}
+
+	return parseArchitecture(data, init)
+}
+
+// parseArchitecture builds a network from the contents of an architecture file, with random weights drawn by init
+func parseArchitecture(data []byte, init Initializer) (Network, error) {
+	var opts NetworkOptions
+
+	if err := json.Unmarshal(data, &opts); err != nil {
+		return Network{}, corrupt(err)
+	}
+
+	if err := checkVersion(opts.Version); err != nil {
+		return Network{}, err
+	}
+
+	for _, name := range opts.Custom {
+		if name != "" {
+			return Network{}, errCustomArchitecture
+		}
+	}
+
+	n, err := opts.network(make([]Layer, len(opts.Sizes)), false)
+	if err != nil {
+		return Network{}, err
+	}
+
+	n.Reinitialize(init)
+
+	return n, nil
+}
@@ -0,0 +1,82 @@
+package nn
+
+import (
+	"archive/zip"
+	"errors"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+var (
+	errInvalidAsset = errors.New("invalid asset name")
+)
+
+// assetPrefix is the directory assets are stored in by Save
+const assetPrefix = "assets/"
+
+// SetAsset stores data under name along with the network, so that whatever prepared its inputs during training, like
+// a vocabulary, is saved and loaded with it. Setting nil data removes the asset. The data is copied.
+func (n *Network) SetAsset(name string, data []byte) error {
+	if name == "" {
+		return errInvalidAsset
+	}
+
+	if data == nil {
+		delete(n.assets, name)
+		return nil
+	}
+
+	if n.assets == nil {
+		n.assets = map[string][]byte{}
+	}
+
+	n.assets[name] = append([]byte(nil), data...)
+	return nil
+}
+
+// Asset returns the data stored under name with SetAsset. The data must not be modified.
+func (n Network) Asset(name string) ([]byte, bool) {
+	data, ok := n.assets[name]
+	return data, ok
+}
+
+// Assets returns the names of every asset stored with the network, in order
+func (n Network) Assets() []string {
+	names := make([]string, 0, len(n.assets))
+
+	for name := range n.assets {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// loadAssets reads the assets saved by Save into the network
+func loadAssets(zipFile *zip.Reader, n *Network) error {
+	for _, f := range zipFile.File {
+		if !strings.HasPrefix(f.Name, assetPrefix) {
+			continue
+		}
+
+		r, err := f.Open()
+		if err != nil {
+			return err
+		}
+
+		data, err := ioutil.ReadAll(r)
+		_ = r.Close()
+
+		if err != nil {
+			return err
+		}
+
+		if err = n.SetAsset(strings.TrimPrefix(f.Name, assetPrefix), data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
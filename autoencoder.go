@@ -0,0 +1,94 @@
+package nn
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/mat"
+	"math/rand"
+)
+
+var (
+	errInvalidCorruption = errors.New("input masking must be in [0, 1) and input noise can't be negative")
+)
+
+// autoencoderLearn is the learning rate of networks made by NewAutoencoder
+const autoencoderLearn = 0.1
+
+// Autoencoder is a network trained to reproduce its input through a narrow layer, whose outputs are a compressed
+// representation of the input
+type Autoencoder struct {
+	Network
+
+	// Bottleneck is the layer, numbered from 0, whose outputs Encode returns
+	Bottleneck int
+}
+
+// NewAutoencoder Creates an autoencoder with random weights and a learning rate of 0.1. The encoder has layers of the
+// hidden sizes followed by the bottleneck, and the decoder mirrors it back to inputSize outputs. Like every layer the
+// outputs use the sigmoid, which suits inputs between 0 and 1, use SetOutputActivation with Linear for others.
+func NewAutoencoder(inputSize, bottleneck int, hidden ...int) Autoencoder {
+	layers := append(append([]int{}, hidden...), bottleneck)
+
+	for i := len(hidden) - 1; i >= 0; i-- {
+		layers = append(layers, hidden[i])
+	}
+
+	return Autoencoder{
+		Network:    NewNetwork(inputSize, inputSize, layers, autoencoderLearn, true),
+		Bottleneck: len(hidden),
+	}
+}
+
+// Train trains the autoencoder to reconstruct each of inputs, printing information on its performance
+func (a *Autoencoder) Train(inputs [][]float64, epochs int) Report {
+	report, err := a.TrainWith(inputs, TrainOptions{Epochs: epochs})
+	if err != nil {
+		panic(err)
+	}
+
+	return report
+}
+
+// TrainWith trains the autoencoder to reconstruct each of inputs like Network.TrainWith. When opts has ValInputs but
+// no ValExpected, the validation inputs are reconstructed too.
+func (a *Autoencoder) TrainWith(inputs [][]float64, opts TrainOptions) (Report, error) {
+	if opts.ValExpected == nil {
+		opts.ValExpected = opts.ValInputs
+	}
+
+	return a.Network.TrainWith(inputs, inputs, opts)
+}
+
+// Encode returns the outputs of the bottleneck layer for a given input
+func (a Autoencoder) Encode(data []float64) []float64 {
+	if err := a.checkInput(data); err != nil {
+		panic(err)
+	}
+
+	_, activations := a.forward(split(data, a.inputs))
+
+	return mat.Col(nil, 0, activations[len(a.inputs)+a.Bottleneck])
+}
+
+// corrupts reports whether opts corrupts training inputs
+func (opts TrainOptions) corrupts() bool {
+	return opts.InputMasking > 0 || opts.InputNoise > 0
+}
+
+// corrupt returns a copy of input corrupted as configured by opts, or input itself if it isn't corrupted
+func (opts TrainOptions) corrupt(input []float64) []float64 {
+	if !opts.corrupts() {
+		return input
+	}
+
+	res := make([]float64, len(input))
+
+	for i, v := range input {
+		if opts.InputMasking > 0 && rand.Float64() < opts.InputMasking {
+			continue
+		}
+
+		res[i] = v + rand.NormFloat64()*opts.InputNoise
+	}
+
+	return res
+}
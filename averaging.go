@@ -0,0 +1,212 @@
+package nn
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/mat"
+)
+
+var (
+	errNoAverage  = errors.New("no weights have been averaged")
+	errNoNetworks = errors.New("no networks given")
+)
+
+// copyParams returns a copy of the parameters of every layer, in the same layout as Gradients
+func (n Network) copyParams() [][]*mat.Dense {
+	res := make([][]*mat.Dense, len(n.layers))
+
+	for i, l := range n.layers {
+		for _, p := range l.params() {
+			res[i] = append(res[i], mat.DenseCopyOf(p))
+		}
+	}
+
+	return res
+}
+
+// loadParams replaces the parameters of every layer with copies of params
+func (n *Network) loadParams(params [][]*mat.Dense) {
+	for i := range n.layers {
+		ps := make([]mat.Matrix, len(params[i]))
+
+		for j, p := range params[i] {
+			ps[j] = mat.DenseCopyOf(p)
+		}
+
+		n.layers[i].setParams(ps)
+	}
+}
+
+// SWA performs stochastic weight averaging, keeping an equally weighted average of the parameters at the end of every
+// epoch from Start onwards. Averaging the tail of training tends to find flatter minima which generalise better. Pass
+// its Callback to TrainWith, then use Apply to put the averaged weights into the network.
+type SWA struct {
+	// Start is the first epoch included in the average
+	Start int
+
+	count int
+	avg   [][]*mat.Dense
+}
+
+// NewSWA Creates a new SWA callback which averages from epoch start onwards
+func NewSWA(start int) *SWA {
+	return &SWA{Start: start}
+}
+
+// Callback adds the network's parameters to the average once Start has been reached
+func (s *SWA) Callback(n *Network, e Epoch) error {
+	if e.Epoch < s.Start {
+		return nil
+	}
+
+	if s.avg == nil {
+		s.avg = n.copyParams()
+		s.count = 1
+		return nil
+	}
+
+	s.count++
+	f := 1 / float64(s.count)
+
+	for i, l := range n.layers {
+		for j, p := range l.params() {
+			s.avg[i][j].Apply(func(y, x int, v float64) float64 {
+				return v + (p.At(y, x)-v)*f
+			}, s.avg[i][j])
+		}
+	}
+
+	return nil
+}
+
+// Count returns the number of epochs averaged so far
+func (s *SWA) Count() int {
+	return s.count
+}
+
+// Apply replaces the parameters of n with the averaged ones
+func (s *SWA) Apply(n *Network) error {
+	if s.avg == nil {
+		return errNoAverage
+	}
+
+	n.loadParams(s.avg)
+	return nil
+}
+
+// EMA keeps an exponential moving average of a network's parameters, updated after every training step. The averaged
+// weights are less noisy than the live ones and are often better for evaluation. Set it as TrainOptions.EMA, then
+// use Calc or Network to evaluate with the averaged weights.
+type EMA struct {
+	// Decay is how much of the average is kept at each step, usually 0.99 or higher
+	Decay float64
+
+	shadow *Network
+}
+
+// NewEMA Creates a new EMA with the given decay
+func NewEMA(decay float64) *EMA {
+	return &EMA{Decay: decay}
+}
+
+// Update moves the average towards the current parameters of n. The first update copies n, which fails if it has a
+// custom layer whose type isn't registered.
+func (e *EMA) Update(n *Network) error {
+	if e.shadow == nil {
+		m, err := n.Clone()
+		if err != nil {
+			return err
+		}
+
+		e.shadow = &m
+		return nil
+	}
+
+	for i, l := range n.layers {
+		params := e.shadow.layers[i].params()
+
+		for j, p := range l.params() {
+			params[j] = add(scl(e.Decay, params[j]), scl(1-e.Decay, p))
+		}
+
+		e.shadow.layers[i].setParams(params)
+	}
+
+	return nil
+}
+
+// Calc evaluates the network with the averaged weights. It must not be called while training is updating the EMA.
+func (e *EMA) Calc(data []float64) []float64 {
+	if e.shadow == nil {
+		panic(errNoAverage)
+	}
+
+	return e.shadow.Calc(data)
+}
+
+// Network returns a copy of the network with the averaged weights
+func (e *EMA) Network() (Network, error) {
+	if e.shadow == nil {
+		return Network{}, errNoAverage
+	}
+
+	return e.shadow.Clone()
+}
+
+// Average returns a network whose parameters are the mean of those of every given network, which must all have the
+// same architecture. Averaging networks fine-tuned from the same starting point (a model soup) often does better than
+// any one of them. The result shares nothing with the inputs, so custom layers must be registered with RegisterLayer.
+func Average(nets ...Network) (Network, error) {
+	weights := make([]float64, len(nets))
+
+	for i := range weights {
+		weights[i] = 1 / float64(len(nets))
+	}
+
+	return weightedSum(nets, weights)
+}
+
+// weightedSum returns a copy of the first network with each parameter replaced by the weighted sum of that parameter
+// over every network
+func weightedSum(nets []Network, weights []float64) (Network, error) {
+	if len(nets) == 0 {
+		return Network{}, errNoNetworks
+	}
+
+	for _, o := range nets[1:] {
+		if !nets[0].sameStructure(o) {
+			return Network{}, ErrIncompatibleArchitectures
+		}
+	}
+
+	res, err := nets[0].Clone()
+	if err != nil {
+		return Network{}, err
+	}
+
+	sum := nets[0].copyParams()
+
+	for _, layer := range sum {
+		for _, p := range layer {
+			p.Scale(weights[0], p)
+		}
+	}
+
+	for k, o := range nets[1:] {
+		for i, l := range o.layers {
+			for j, p := range l.params() {
+				sum[i][j].Add(sum[i][j], scl(weights[k+1], p))
+			}
+		}
+	}
+
+	res.loadParams(sum)
+
+	return res, nil
+}
+
+// Lerp interpolates between the parameters of two networks with the same architecture, returning a at t = 0 and b at
+// t = 1. Evaluating the cost along the way shows the shape of the loss landscape between two solutions, and stepping t
+// up gradually rolls a new model out smoothly. t outside 0 to 1 extrapolates.
+func Lerp(a, b Network, t float64) (Network, error) {
+	return weightedSum([]Network{a, b}, []float64{1 - t, t})
+}
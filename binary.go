@@ -0,0 +1,200 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+var (
+	errNotBinary = errors.New("binary classification needs a network with a single output")
+	errNoSamples = errors.New("no samples to evaluate")
+)
+
+// NewBinaryClassifier Creates a network for yes/no decisions, with a single sigmoid output trained with CrossEntropy
+func NewBinaryClassifier(inputs int, hidden []int, learn float64, random bool) Network {
+	n := NewNetwork(inputs, 1, hidden, learn, random)
+	_ = n.SetOutputActivation(Sigmoid)
+	n.heads[0].loss = CrossEntropy
+
+	return n
+}
+
+// PredictProb returns the output of a network with a single output, which for a binary classifier is the probability
+// of the positive class
+func (n Network) PredictProb(data []float64) float64 {
+	if n.o != 1 {
+		panic(errNotBinary)
+	}
+
+	return n.Calc(data)[0]
+}
+
+// PredictLabel reports whether the output of a network with a single output is at least threshold
+func (n Network) PredictLabel(data []float64, threshold float64) bool {
+	return n.PredictProb(data) >= threshold
+}
+
+// BinaryMetrics counts the outcomes of a binary classifier at a threshold
+type BinaryMetrics struct {
+	Threshold float64 `json:"threshold"`
+
+	TruePositives  int `json:"true_positives"`
+	FalsePositives int `json:"false_positives"`
+	TrueNegatives  int `json:"true_negatives"`
+	FalseNegatives int `json:"false_negatives"`
+}
+
+// Accuracy returns the fraction of samples classified correctly
+func (m BinaryMetrics) Accuracy() float64 {
+	return ratio(m.TruePositives+m.TrueNegatives, m.TruePositives+m.TrueNegatives+m.FalsePositives+m.FalseNegatives)
+}
+
+// Precision returns the fraction of samples predicted positive which are positive
+func (m BinaryMetrics) Precision() float64 {
+	return ratio(m.TruePositives, m.TruePositives+m.FalsePositives)
+}
+
+// Recall returns the fraction of positive samples predicted positive
+func (m BinaryMetrics) Recall() float64 {
+	return ratio(m.TruePositives, m.TruePositives+m.FalseNegatives)
+}
+
+// Specificity returns the fraction of negative samples predicted negative
+func (m BinaryMetrics) Specificity() float64 {
+	return ratio(m.TrueNegatives, m.TrueNegatives+m.FalsePositives)
+}
+
+// F1 returns the harmonic mean of the precision and recall
+func (m BinaryMetrics) F1() float64 {
+	return ratio(2*m.TruePositives, 2*m.TruePositives+m.FalsePositives+m.FalseNegatives)
+}
+
+// YoudenJ returns the recall plus the specificity minus 1, which is highest at the threshold furthest above chance
+// on the ROC curve
+func (m BinaryMetrics) YoudenJ() float64 {
+	return m.Recall() + m.Specificity() - 1
+}
+
+// ratio divides a by b, treating 0/0 as 0
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 0
+	}
+
+	return float64(a) / float64(b)
+}
+
+// EvaluateBinary counts the outcomes of classifying every input at a threshold
+func (n Network) EvaluateBinary(inputs [][]float64, labels []bool, threshold float64) (BinaryMetrics, error) {
+	probs, err := n.binaryProbs(inputs, labels)
+	if err != nil {
+		return BinaryMetrics{}, err
+	}
+
+	m := BinaryMetrics{Threshold: threshold}
+
+	for i, p := range probs {
+		switch {
+		case p >= threshold && labels[i]:
+			m.TruePositives++
+		case p >= threshold:
+			m.FalsePositives++
+		case labels[i]:
+			m.FalseNegatives++
+		default:
+			m.TrueNegatives++
+		}
+	}
+
+	return m, nil
+}
+
+// TuneThreshold tries every threshold that changes a prediction and returns the metrics of the one with the highest
+// score, such as BinaryMetrics.F1 or BinaryMetrics.YoudenJ, or F1 when score is nil. It should be tuned on a
+// validation set rather than the training data.
+func (n Network) TuneThreshold(inputs [][]float64, labels []bool, score func(BinaryMetrics) float64) (BinaryMetrics,
+	error) {
+	probs, err := n.binaryProbs(inputs, labels)
+	if err != nil {
+		return BinaryMetrics{}, err
+	}
+
+	if score == nil {
+		score = BinaryMetrics.F1
+	}
+
+	order := make([]int, len(probs))
+
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.Slice(order, func(a, b int) bool {
+		return probs[order[a]] > probs[order[b]]
+	})
+
+	// Lowering the threshold past each probability in turn moves samples from negative to positive predictions,
+	// starting from a threshold above every probability
+	m := BinaryMetrics{Threshold: math.Nextafter(probs[order[0]], math.Inf(1))}
+
+	for _, l := range labels {
+		if l {
+			m.FalseNegatives++
+		} else {
+			m.TrueNegatives++
+		}
+	}
+
+	best, bestScore := m, score(m)
+
+	for i, j := range order {
+		if labels[j] {
+			m.FalseNegatives--
+			m.TruePositives++
+		} else {
+			m.TrueNegatives--
+			m.FalsePositives++
+		}
+
+		if i+1 < len(order) && probs[order[i+1]] == probs[j] {
+			continue
+		}
+
+		m.Threshold = probs[j]
+
+		if s := score(m); s > bestScore {
+			best, bestScore = m, s
+		}
+	}
+
+	return best, nil
+}
+
+// binaryProbs returns the output of a network with a single output for every input
+func (n Network) binaryProbs(inputs [][]float64, labels []bool) ([]float64, error) {
+	if n.o != 1 {
+		return nil, errNotBinary
+	}
+
+	if len(inputs) == 0 {
+		return nil, errNoSamples
+	}
+
+	if len(inputs) != len(labels) {
+		return nil, fmt.Errorf("%w: %d inputs but %d labels", ErrDimensionMismatch, len(inputs), len(labels))
+	}
+
+	probs := make([]float64, len(inputs))
+
+	for i, input := range inputs {
+		if err := n.checkInput(input); err != nil {
+			return nil, fmt.Errorf("sample %d: %w", i, err)
+		}
+
+		probs[i] = n.Calc(input)[0]
+	}
+
+	return probs, nil
+}
@@ -0,0 +1,222 @@
+package nn
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	errNotTwoInputs = errors.New("decision boundaries need a network with 2 inputs")
+)
+
+// defaultGridSize is the number of points along each side of a decision grid when none is given
+const defaultGridSize = 200
+
+// boundaryPalette colours the classes of a decision boundary plot, in order
+var boundaryPalette = []color.RGBA{
+	{R: 31, G: 119, B: 180, A: 255},
+	{R: 214, G: 39, B: 40, A: 255},
+	{R: 44, G: 160, B: 44, A: 255},
+	{R: 255, G: 127, B: 14, A: 255},
+	{R: 148, G: 103, B: 189, A: 255},
+	{R: 140, G: 86, B: 75, A: 255},
+}
+
+// GridOptions configures DecisionGrid and PlotDecisionBoundary
+type GridOptions struct {
+	// MinX, MaxX, MinY and MaxY bound the area evaluated, which is 0 to 1 along an axis whose bounds are equal
+	MinX, MaxX, MinY, MaxY float64
+
+	// Size is the number of points along each side of the grid, 200 when zero
+	Size int
+
+	// Output is the output whose value is used at each point. When it is negative the index of the largest output is
+	// used instead, for classifiers with one output per class.
+	Output int
+
+	// Points are drawn on top of plotted boundaries, usually the training inputs
+	Points [][]float64
+}
+
+// bounds fills in the defaults of the options
+func (opts GridOptions) bounds() GridOptions {
+	if opts.MinX == opts.MaxX {
+		opts.MinX, opts.MaxX = 0, 1
+	}
+
+	if opts.MinY == opts.MaxY {
+		opts.MinY, opts.MaxY = 0, 1
+	}
+
+	if opts.Size < 2 {
+		opts.Size = defaultGridSize
+	}
+
+	return opts
+}
+
+// DecisionGrid evaluates a network with 2 inputs over an evenly spaced grid of points. grid[row][col] holds the value
+// at x = MinX + col*(MaxX-MinX)/(Size-1) and y = MaxY - row*(MaxY-MinY)/(Size-1), so the first row is the top of the
+// area as it would be drawn.
+func (n Network) DecisionGrid(opts GridOptions) ([][]float64, error) {
+	if n.i != 2 {
+		return nil, errNotTwoInputs
+	}
+
+	if opts.Output >= n.o {
+		return nil, ErrDimensionMismatch
+	}
+
+	opts = opts.bounds()
+	grid := make([][]float64, opts.Size)
+
+	for row := range grid {
+		grid[row] = make([]float64, opts.Size)
+		y := lerp(float64(row), 0, float64(opts.Size-1), opts.MaxY, opts.MinY)
+
+		for col := range grid[row] {
+			x := lerp(float64(col), 0, float64(opts.Size-1), opts.MinX, opts.MaxX)
+			res := n.Calc([]float64{x, y})
+
+			if opts.Output >= 0 {
+				grid[row][col] = res[opts.Output]
+				continue
+			}
+
+			for k, v := range res {
+				if v > res[int(grid[row][col])] {
+					grid[row][col] = float64(k)
+				}
+			}
+		}
+	}
+
+	return grid, nil
+}
+
+// PlotDecisionBoundary writes the decision surface of a network with 2 inputs to a file, chosen by its extension. PNG
+// images colour a single output from blue at 0 to red at 1 with a dark line where it crosses 0.5, or colour each
+// point by its class when opts.Output is negative. CSV files hold the grid of DecisionGrid, one row per line.
+func (n Network) PlotDecisionBoundary(filename string, opts GridOptions) error {
+	grid, err := n.DecisionGrid(opts)
+	if err != nil {
+		return err
+	}
+
+	var write func(w io.Writer) error
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".png":
+		write = func(w io.Writer) error {
+			return png.Encode(w, boundaryImage(grid, opts.bounds()))
+		}
+	case ".csv":
+		write = func(w io.Writer) error {
+			return writeGrid(w, grid)
+		}
+	default:
+		return errUnknownPlotFormat
+	}
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+
+	if err = write(w); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	if err = w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// boundaryImage draws a decision grid one pixel per point
+func boundaryImage(grid [][]float64, opts GridOptions) *image.RGBA {
+	var (
+		size = len(grid)
+		img  = image.NewRGBA(image.Rect(0, 0, size, size))
+		low  = boundaryPalette[0]
+		high = boundaryPalette[1]
+	)
+
+	for row := range grid {
+		for col, v := range grid[row] {
+			if opts.Output < 0 {
+				img.SetRGBA(col, row, boundaryPalette[int(v)%len(boundaryPalette)])
+				continue
+			}
+
+			f := math.Max(0, math.Min(1, v))
+			c := color.RGBA{
+				R: uint8(lerp(f, 0, 1, float64(low.R), float64(high.R))),
+				G: uint8(lerp(f, 0, 1, float64(low.G), float64(high.G))),
+				B: uint8(lerp(f, 0, 1, float64(low.B), float64(high.B))),
+				A: 255,
+			}
+
+			// Mark points where the output crosses 0.5 from the point to their left or above
+			if (col > 0 && (v >= 0.5) != (grid[row][col-1] >= 0.5)) ||
+				(row > 0 && (v >= 0.5) != (grid[row-1][col] >= 0.5)) {
+				c = color.RGBA{A: 255}
+			}
+
+			img.SetRGBA(col, row, c)
+		}
+	}
+
+	for _, p := range opts.Points {
+		if len(p) != 2 {
+			continue
+		}
+
+		x := int(math.Round(lerp(p[0], opts.MinX, opts.MaxX, 0, float64(size-1))))
+		y := int(math.Round(lerp(p[1], opts.MaxY, opts.MinY, 0, float64(size-1))))
+
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				img.SetRGBA(x+dx, y+dy, color.RGBA{A: 255})
+			}
+		}
+	}
+
+	return img
+}
+
+// writeGrid writes a grid of values as CSV
+func writeGrid(w io.Writer, grid [][]float64) error {
+	cw := csv.NewWriter(w)
+
+	for _, row := range grid {
+		record := make([]string, len(row))
+
+		for i, v := range row {
+			record[i] = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
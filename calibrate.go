@@ -0,0 +1,117 @@
+package nn
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	errInvalidTemperature = errors.New("invalid temperature")
+	errNothingToCalibrate = errors.New("network has no sigmoid or softmax output layers to calibrate")
+)
+
+// minTemperature and maxTemperature bound the temperature Calibrate searches over
+const (
+	minTemperature = 1e-2
+	maxTemperature = 1e2
+)
+
+// calibrated returns the layers the temperature applies to, which are the dense output layers with a sigmoid or
+// softmax activation
+func (n Network) calibrated() []int {
+	var res []int
+
+	for _, a := range n.outputs {
+		if a < len(n.inputs) {
+			continue
+		}
+
+		l := n.layers[a-len(n.inputs)]
+
+		if l.custom == nil && (l.activation == Sigmoid || l.activation == Softmax) {
+			res = append(res, a-len(n.inputs))
+		}
+	}
+
+	return res
+}
+
+// SetTemperature divides the weighted input of every dense output layer with a sigmoid or softmax activation by t,
+// which makes the outputs less confident when t is above 1 and more confident below it. A temperature of 1 leaves the
+// network unchanged.
+func (n *Network) SetTemperature(t float64) error {
+	if t <= 0 || math.IsInf(t, 0) || math.IsNaN(t) {
+		return errInvalidTemperature
+	}
+
+	for _, i := range n.calibrated() {
+		n.layers[i].temperature = t
+	}
+
+	return nil
+}
+
+// Temperature returns the temperature set by SetTemperature or Calibrate, or 1 if there isn't one
+func (n Network) Temperature() float64 {
+	for _, i := range n.calibrated() {
+		if n.layers[i].temperature > 0 {
+			return n.layers[i].temperature
+		}
+	}
+
+	return 1
+}
+
+// Calibrate fits the temperature of the network to a validation set by minimising the network's cost on it over the
+// temperature alone, and returns the temperature found. Networks trained to fit their training set closely are often
+// overconfident, and calibrating them with cross entropy outputs makes their outputs closer to real probabilities.
+// The temperature is saved along with the network and used by Calc.
+func (n *Network) Calibrate(inputs, expected [][]float64) (float64, error) {
+	if len(inputs) == 0 {
+		return 0, ErrDimensionMismatch
+	}
+
+	if err := n.checkSamples(inputs, expected); err != nil {
+		return 0, err
+	}
+
+	if len(n.calibrated()) == 0 {
+		return 0, errNothingToCalibrate
+	}
+
+	cost := func(logT float64) float64 {
+		_ = n.SetTemperature(math.Exp(logT))
+		return n.Cost(inputs, expected)
+	}
+
+	// Golden section search over the log of the temperature
+	var (
+		ratio = (math.Sqrt(5) - 1) / 2
+		lo    = math.Log(minTemperature)
+		hi    = math.Log(maxTemperature)
+		a     = hi - ratio*(hi-lo)
+		b     = lo + ratio*(hi-lo)
+		ca    = cost(a)
+		cb    = cost(b)
+	)
+
+	for hi-lo > 1e-4 {
+		if ca < cb {
+			hi, b, cb = b, a, ca
+			a = hi - ratio*(hi-lo)
+			ca = cost(a)
+		} else {
+			lo, a, ca = a, b, cb
+			b = lo + ratio*(hi-lo)
+			cb = cost(b)
+		}
+	}
+
+	t := math.Exp((lo + hi) / 2)
+
+	if err := n.SetTemperature(t); err != nil {
+		return 0, err
+	}
+
+	return t, nil
+}
@@ -0,0 +1,106 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+var (
+	errNoCheckpoint = errors.New("no checkpoint has been taken")
+)
+
+// BestCheckpoint keeps the weights from the epoch with the best value of a metric, so that a run which overfits or
+// becomes unstable towards its end still produces its best model. Pass its Callback to TrainWith, then use Restore to
+// put the best weights back into the network once training has finished.
+type BestCheckpoint struct {
+	// Filename, when set, is where the network is saved with Save every time the metric improves
+	Filename string
+
+	// Metric returns the value to compare epochs by, the validation cost when nil, or the training cost when there is
+	// no validation set
+	Metric func(e Epoch) float64
+
+	// Maximize keeps the epoch with the highest value of the metric instead of the lowest
+	Maximize bool
+
+	best   float64
+	epoch  int
+	params [][]*mat.Dense
+}
+
+// NewBestCheckpoint Creates a checkpoint keeping the weights with the lowest validation cost, saving them to filename
+// unless it is empty
+func NewBestCheckpoint(filename string) *BestCheckpoint {
+	return &BestCheckpoint{Filename: filename}
+}
+
+// Callback takes a checkpoint if the epoch is the best so far
+func (b *BestCheckpoint) Callback(n *Network, e Epoch) error {
+	value := b.value(e)
+
+	if math.IsNaN(value) || b.params != nil && (b.Maximize && value <= b.best || !b.Maximize && value >= b.best) {
+		return nil
+	}
+
+	b.best, b.epoch, b.params = value, e.Epoch, n.copyParams()
+
+	if b.Filename == "" {
+		return nil
+	}
+
+	if err := n.Save(b.Filename); err != nil {
+		return fmt.Errorf("saving checkpoint of epoch %d: %w", e.Epoch, err)
+	}
+
+	return nil
+}
+
+// value returns the metric of an epoch
+func (b *BestCheckpoint) value(e Epoch) float64 {
+	if b.Metric != nil {
+		return b.Metric(e)
+	}
+
+	if e.HasValidation {
+		return e.ValLoss
+	}
+
+	return e.Loss
+}
+
+// Best returns the number of the best epoch so far and its value of the metric, or an epoch of 0 if no checkpoint
+// has been taken
+func (b *BestCheckpoint) Best() (epoch int, value float64) {
+	return b.epoch, b.best
+}
+
+// Restore replaces the network's parameters with those of the best epoch
+func (b *BestCheckpoint) Restore(n *Network) error {
+	if b.params == nil {
+		return errNoCheckpoint
+	}
+
+	if len(n.layers) != len(b.params) {
+		return ErrIncompatibleArchitectures
+	}
+
+	for i, l := range n.layers {
+		params := l.params()
+
+		if len(params) != len(b.params[i]) {
+			return ErrIncompatibleArchitectures
+		}
+
+		for j, p := range params {
+			if !sameDims(p, b.params[i][j]) {
+				return ErrIncompatibleArchitectures
+			}
+		}
+	}
+
+	n.loadParams(b.params)
+
+	return nil
+}
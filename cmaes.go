@@ -0,0 +1,252 @@
+package nn
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+var (
+	errInvalidCMAES = errors.New("CMA-ES needs positive generations and a network with parameters")
+)
+
+// CMAES trains a network without gradients using the covariance matrix adaptation evolution strategy. Every generation
+// it samples candidate parameter vectors from a normal distribution around the current ones, and moves the mean, step
+// size and shape of the distribution towards the best candidates. It learns which directions in weight space matter,
+// so it makes far better use of its evaluations than hill climbing with Perturb. It suits objectives without useful
+// gradients, such as the total reward of a control policy, but stores a matrix of size parameters², so is best kept
+// to small networks.
+type CMAES struct {
+	// StepSize is the standard deviation the search starts with, 0.5 when zero
+	StepSize float64
+
+	// Population is the number of candidates evaluated every generation, 4+3ln(parameters) when zero
+	Population int
+
+	// Generations is the maximum number of generations. The search stops early once the step size collapses.
+	Generations int
+}
+
+// Train minimises the objective over the network's parameters starting from their current values, leaves the network
+// with the best parameters found, and returns the lowest value of the objective in every generation. The objective is
+// given a copy of the network with a candidate's parameters, and is called for every candidate of a generation at the
+// same time, so it must be safe for concurrent use. Negate rewards to maximise them. Candidates are clones of the
+// network, so custom layers must be registered with RegisterLayer.
+func (c CMAES) Train(n *Network, objective func(n Network) float64) ([]float64, error) {
+	mean := n.Params()
+	dim := len(mean)
+
+	if c.Generations < 1 || dim == 0 {
+		return nil, errInvalidCMAES
+	}
+
+	sigma, lambda := c.StepSize, c.Population
+
+	if sigma == 0 {
+		sigma = 0.5
+	}
+
+	if lambda == 0 {
+		lambda = 4 + int(3*math.Log(float64(dim)))
+	}
+
+	if lambda < 2 {
+		lambda = 2
+	}
+
+	// Selection and adaptation constants, as recommended by Hansen's tutorial
+	var (
+		mu      = lambda / 2
+		weights = make([]float64, mu)
+		sum     = 0.0
+		sumSq   = 0.0
+		size    = float64(dim)
+	)
+
+	for i := range weights {
+		weights[i] = math.Log(float64(mu)+0.5) - math.Log(float64(i+1))
+		sum += weights[i]
+	}
+
+	for i := range weights {
+		weights[i] /= sum
+		sumSq += weights[i] * weights[i]
+	}
+
+	var (
+		muEff = 1 / sumSq
+		cc    = (4 + muEff/size) / (size + 4 + 2*muEff/size)
+		cs    = (muEff + 2) / (size + muEff + 5)
+		c1    = 2 / ((size+1.3)*(size+1.3) + muEff)
+		cmu   = math.Min(1-c1, 2*(muEff-2+1/muEff)/((size+2)*(size+2)+muEff))
+		damps = 1 + 2*math.Max(0, math.Sqrt((muEff-1)/(size+1))-1) + cs
+		chiN  = math.Sqrt(size) * (1 - 1/(4*size) + 1/(21*size*size))
+	)
+
+	var (
+		m      = mat.NewVecDense(dim, mean)
+		pc     = mat.NewVecDense(dim, nil)
+		ps     = mat.NewVecDense(dim, nil)
+		cov    = mat.NewSymDense(dim, nil)
+		b      = mat.NewDense(dim, dim, nil)
+		d      = make([]float64, dim)
+		best   = append([]float64(nil), mean...)
+		bestF  = math.Inf(1)
+		losses = make([]float64, 0, c.Generations)
+		stale  = 0 // stale counts the evaluations since the eigendecomposition of the covariance
+	)
+
+	for i := 0; i < dim; i++ {
+		cov.SetSym(i, i, 1)
+		b.Set(i, i, 1)
+		d[i] = 1
+	}
+
+	for gen := 0; gen < c.Generations; gen++ {
+		xs := make([]*mat.VecDense, lambda)
+		ys := make([]*mat.VecDense, lambda)
+		fs := make([]float64, lambda)
+
+		for k := range xs {
+			z := mat.NewVecDense(dim, nil)
+
+			for i := 0; i < dim; i++ {
+				z.SetVec(i, d[i]*rand.NormFloat64())
+			}
+
+			ys[k] = mat.NewVecDense(dim, nil)
+			ys[k].MulVec(b, z)
+
+			xs[k] = mat.NewVecDense(dim, nil)
+			xs[k].AddScaledVec(m, sigma, ys[k])
+		}
+
+		jobs := make([]func() error, lambda)
+
+		for k := range jobs {
+			k := k
+
+			jobs[k] = func() error {
+				candidate, err := n.Clone()
+				if err != nil {
+					return err
+				}
+
+				if err = candidate.SetParams(xs[k].RawVector().Data); err != nil {
+					return err
+				}
+
+				if fs[k] = objective(candidate); math.IsNaN(fs[k]) {
+					fs[k] = math.Inf(1)
+				}
+
+				return nil
+			}
+		}
+
+		if err := parallel(jobs); err != nil {
+			return losses, err
+		}
+
+		order := make([]int, lambda)
+
+		for k := range order {
+			order[k] = k
+		}
+
+		sort.SliceStable(order, func(a, b int) bool {
+			return fs[order[a]] < fs[order[b]]
+		})
+
+		losses = append(losses, fs[order[0]])
+
+		if fs[order[0]] < bestF {
+			bestF = fs[order[0]]
+			best = append(best[:0], xs[order[0]].RawVector().Data...)
+		}
+
+		// The mean moves to the weighted average of the best candidates, a step of yw in units of sigma
+		yw := mat.NewVecDense(dim, nil)
+
+		for i := 0; i < mu; i++ {
+			yw.AddScaledVec(yw, weights[i], ys[order[i]])
+		}
+
+		m.AddScaledVec(m, sigma, yw)
+
+		// The conjugate evolution path tracks steps whitened by C^-1/2 = B D^-1 B^T, and sets the step size
+		whitened := mat.NewVecDense(dim, nil)
+		whitened.MulVec(b.T(), yw)
+
+		for i := 0; i < dim; i++ {
+			whitened.SetVec(i, whitened.AtVec(i)/d[i])
+		}
+
+		step := mat.NewVecDense(dim, nil)
+		step.MulVec(b, whitened)
+		ps.ScaleVec(1-cs, ps)
+		ps.AddScaledVec(ps, math.Sqrt(cs*(2-cs)*muEff), step)
+
+		psNorm := mat.Norm(ps, 2)
+		hsig := 0.0
+
+		if psNorm/math.Sqrt(1-math.Pow(1-cs, 2*float64(gen+1)))/chiN < 1.4+2/(size+1) {
+			hsig = 1
+		}
+
+		pc.ScaleVec(1-cc, pc)
+		pc.AddScaledVec(pc, hsig*math.Sqrt(cc*(2-cc)*muEff), yw)
+
+		// The covariance learns from the evolution path (rank one) and the spread of the best candidates (rank mu)
+		cov.ScaleSym(1-c1-cmu+(1-hsig)*c1*cc*(2-cc), cov)
+		cov.SymRankOne(cov, c1, pc)
+
+		for i := 0; i < mu; i++ {
+			cov.SymRankOne(cov, cmu*weights[i], ys[order[i]])
+		}
+
+		sigma *= math.Exp(cs / damps * (psNorm/chiN - 1))
+
+		// Decomposing the covariance is the most expensive step, so it is only repeated once it has changed enough
+		stale += lambda
+
+		if float64(stale) > float64(lambda)/(c1+cmu)/size/10 {
+			stale = 0
+
+			var eig mat.EigenSym
+
+			if !eig.Factorize(cov, true) {
+				break
+			}
+
+			eig.VectorsTo(b)
+
+			for i, v := range eig.Values(nil) {
+				d[i] = math.Sqrt(math.Max(v, 1e-20))
+			}
+		}
+
+		if sigma*floatsMax(d) < 1e-12 {
+			break
+		}
+	}
+
+	if err := n.SetParams(best); err != nil {
+		return losses, err
+	}
+
+	return losses, nil
+}
+
+// floatsMax returns the largest of a set of values
+func floatsMax(v []float64) float64 {
+	res := math.Inf(-1)
+
+	for _, x := range v {
+		res = math.Max(res, x)
+	}
+
+	return res
+}
@@ -0,0 +1,217 @@
+// Command nn trains networks from CSV files, runs predictions with them and describes saved models.
+//
+// Usage:
+//
+//	nn train -data train.csv -outputs 1 -hidden 8,4 -epochs 100 -out model.zip
+//	nn predict -model model.zip -data rows.csv
+//	nn info -model model.zip
+//
+// Training rows hold the inputs followed by the expected outputs. Prediction rows hold just the inputs, and the
+// outputs are written to stdout as CSV.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/e74000/nn"
+	"os"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+
+	switch os.Args[1] {
+	case "train":
+		err = train(os.Args[2:])
+	case "predict":
+		err = predict(os.Args[2:])
+	case "info":
+		err = info(os.Args[2:])
+	default:
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "nn:", err)
+		os.Exit(1)
+	}
+}
+
+// usage prints how to use the command and exits
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: nn train|predict|info [flags]")
+	os.Exit(2)
+}
+
+// train trains a new network from a CSV file and saves it
+func train(args []string) error {
+	fs := flag.NewFlagSet("train", flag.ExitOnError)
+
+	var (
+		data    = fs.String("data", "", "CSV file of inputs followed by expected outputs")
+		header  = fs.Bool("header", false, "skip the first row of the CSV file")
+		outputs = fs.Int("outputs", 1, "number of output columns at the end of each row")
+		hidden  = fs.String("hidden", "8", "comma separated sizes of the hidden layers")
+		learn   = fs.Float64("learn", 0.1, "learning rate")
+		epochs  = fs.Int("epochs", 100, "number of epochs to train for")
+		linear  = fs.Bool("linear", false, "shorthand for -output linear, for regression")
+		output  = fs.String("output", "sigmoid", "output activation: sigmoid, linear or softmax")
+		out     = fs.String("out", "model.zip", "file to save the trained network to")
+		report  = fs.String("report", "", "file to write a JSON report of the training run to")
+	)
+
+	_ = fs.Parse(args)
+
+	if *outputs < 1 {
+		return fmt.Errorf("-outputs must be at least 1")
+	}
+
+	samples, err := readCSV(*data, *outputs, *header)
+	if err != nil {
+		return err
+	}
+
+	if len(samples.Inputs) == 0 {
+		return fmt.Errorf("%s has no rows", *data)
+	}
+
+	sizes, err := parseSizes(*hidden)
+	if err != nil {
+		return err
+	}
+
+	n := nn.NewNetwork(len(samples.Inputs[0]), *outputs, sizes, *learn, true)
+
+	if *linear {
+		if *output != nn.Sigmoid.Name && *output != nn.Linear.Name {
+			return fmt.Errorf("-linear conflicts with -output %s", *output)
+		}
+
+		*output = nn.Linear.Name
+	}
+
+	switch *output {
+	case nn.Sigmoid.Name:
+	case nn.Linear.Name:
+		if err = n.SetOutputActivation(nn.Linear); err != nil {
+			return err
+		}
+	case nn.Softmax.Name:
+		err = n.SetHead(0, nn.Head{Activation: nn.Softmax, Loss: nn.CategoricalCrossEntropy})
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown output activation %q", *output)
+	}
+
+	r := n.Train(samples.Inputs, samples.Expected, *epochs)
+
+	if *report != "" {
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		err = os.WriteFile(*report, data, 0o644)
+		if err != nil {
+			return err
+		}
+	}
+
+	return n.Save(*out)
+}
+
+// predict writes the outputs of a saved network for every row of a CSV file
+func predict(args []string) error {
+	fs := flag.NewFlagSet("predict", flag.ExitOnError)
+
+	var (
+		model   = fs.String("model", "model.zip", "saved network")
+		data    = fs.String("data", "", "CSV file of inputs")
+		header  = fs.Bool("header", false, "skip the first row of the CSV file")
+		echo    = fs.Bool("echo", false, "write each row's inputs before its outputs")
+		workers = fs.Int("workers", 1, "number of goroutines evaluating rows")
+	)
+
+	_ = fs.Parse(args)
+
+	n, err := nn.Load(*model)
+	if err != nil {
+		return err
+	}
+
+	samples, err := readCSV(*data, 0, *header)
+	if err != nil {
+		return err
+	}
+
+	inputs, _ := n.Dims()
+
+	for i, row := range samples.Inputs {
+		if len(row) < inputs {
+			return fmt.Errorf("row %d has %d columns, the network expects %d inputs", i+1, len(row), inputs)
+		}
+
+		samples.Inputs[i] = row[:inputs]
+	}
+
+	return n.PredictCSV(os.Stdout, samples, nn.PredictCSVOptions{Echo: *echo, Workers: *workers})
+}
+
+// info prints a summary of a saved network
+func info(args []string) error {
+	fs := flag.NewFlagSet("info", flag.ExitOnError)
+	model := fs.String("model", "model.zip", "saved network")
+
+	_ = fs.Parse(args)
+
+	n, err := nn.Load(*model)
+	if err != nil {
+		return err
+	}
+
+	fmt.Print(n.Summary())
+
+	return nil
+}
+
+// readCSV reads samples from a CSV file of numbers, with the last outputs columns of each row holding its expected
+// outputs
+func readCSV(filename string, outputs int, header bool) (nn.Samples, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nn.Samples{}, err
+	}
+
+	defer f.Close()
+
+	return nn.ReadCSV(f, outputs, header)
+}
+
+// parseSizes reads a comma separated list of layer sizes
+func parseSizes(s string) ([]int, error) {
+	var sizes []int
+
+	for _, field := range strings.Split(s, ",") {
+		if strings.TrimSpace(field) == "" {
+			continue
+		}
+
+		size, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || size < 1 {
+			return nil, fmt.Errorf("invalid layer size %q", field)
+		}
+
+		sizes = append(sizes, size)
+	}
+
+	return sizes, nil
+}
@@ -0,0 +1,143 @@
+package nn
+
+import (
+	"math"
+)
+
+// LayerDiff describes how far the parameters of a layer in one network are from those of the same layer in another
+type LayerDiff struct {
+	// Max and Mean are the largest and average absolute difference between corresponding parameters
+	Max, Mean float64
+}
+
+// sameShape checks that two networks have parameters of the same sizes in the same layers
+func (n Network) sameShape(o Network) bool {
+	if len(n.layers) != len(o.layers) {
+		return false
+	}
+
+	for i := range n.layers {
+		params := n.layers[i].params()
+		other := o.layers[i].params()
+
+		if len(params) != len(other) {
+			return false
+		}
+
+		for j := range params {
+			r, c := params[j].Dims()
+			or, oc := other[j].Dims()
+
+			if r != or || c != oc {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// sameStructure checks that two networks are wired up the same way, with the same activations, heads, dropout rates,
+// weight ties and temperature
+func (n Network) sameStructure(o Network) bool {
+	if n.i != o.i || n.o != o.o || len(n.inputs) != len(o.inputs) || len(n.outputs) != len(o.outputs) ||
+		len(n.heads) != len(o.heads) || n.Temperature() != o.Temperature() || !n.sameShape(o) {
+		return false
+	}
+
+	for i := range n.inputs {
+		if n.inputs[i] != o.inputs[i] {
+			return false
+		}
+	}
+
+	for i := range n.outputs {
+		if n.outputs[i] != o.outputs[i] || n.heads[i] != o.heads[i] {
+			return false
+		}
+	}
+
+	for i, l := range n.layers {
+		ol := o.layers[i]
+
+		if l.from != ol.from || l.activation != ol.activation || len(l.skips) != len(ol.skips) ||
+			(l.custom == nil) != (ol.custom == nil) || (l.biases == nil) != (ol.biases == nil) {
+			return false
+		}
+
+		if l.dropout != ol.dropout {
+			return false
+		}
+
+		if (l.tie == nil) != (ol.tie == nil) || l.tie != nil && *l.tie != *ol.tie {
+			return false
+		}
+
+		if l.custom != nil && l.custom.Name() != ol.custom.Name() {
+			return false
+		}
+
+		for j := range l.skips {
+			if l.skips[j].from != ol.skips[j].from || (l.skips[j].weights == nil) != (ol.skips[j].weights == nil) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Diff compares the parameters of two networks with the same architecture layer by layer
+func (n Network) Diff(o Network) ([]LayerDiff, error) {
+	if !n.sameShape(o) {
+		return nil, ErrIncompatibleArchitectures
+	}
+
+	res := make([]LayerDiff, len(n.layers))
+
+	for i := range n.layers {
+		other := o.layers[i].params()
+		count := 0
+
+		for j, p := range n.layers[i].params() {
+			r, c := p.Dims()
+
+			for y := 0; y < r; y++ {
+				for x := 0; x < c; x++ {
+					d := math.Abs(p.At(y, x) - other[j].At(y, x))
+
+					res[i].Max = math.Max(res[i].Max, d)
+					res[i].Mean += d
+					count++
+				}
+			}
+		}
+
+		if count > 0 {
+			res[i].Mean /= float64(count)
+		}
+	}
+
+	return res, nil
+}
+
+// Equal reports whether two networks have the same architecture and every pair of corresponding parameters differs
+// by no more than tol
+func Equal(a, b Network, tol float64) bool {
+	if !a.sameStructure(b) {
+		return false
+	}
+
+	diffs, err := a.Diff(b)
+	if err != nil {
+		return false
+	}
+
+	for _, d := range diffs {
+		if d.Max > tol {
+			return false
+		}
+	}
+
+	return true
+}
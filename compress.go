@@ -0,0 +1,132 @@
+package nn
+
+import (
+	"errors"
+	"math"
+	"sort"
+)
+
+var (
+	errInvalidUpdate = errors.New("invalid compressed update")
+)
+
+// Compression configures how workers compress the updates they send to an AveragingServer
+type Compression struct {
+	// TopK is the fraction of the largest changes sent in each update, or every change when zero
+	TopK float64
+
+	// Quantize sends each change as a single byte rather than a float64
+	Quantize bool
+}
+
+// CompressedUpdate is a compressed change to a vector of parameters
+type CompressedUpdate struct {
+	// Size is the length of the full vector
+	Size int
+
+	// Indices holds the position of each value sent, or is nil when every value is sent
+	Indices []uint32
+
+	// Values holds the values sent when they aren't quantized
+	Values []float64
+
+	// Quantized holds the values sent when they are quantized, as offsets from 128 in units of Scale
+	Quantized []byte
+	Scale     float64
+}
+
+// compress encodes a vector of changes
+func (c Compression) compress(v []float64) CompressedUpdate {
+	u := CompressedUpdate{Size: len(v)}
+	values := v
+
+	if c.TopK > 0 && c.TopK < 1 {
+		k := int(math.Ceil(c.TopK * float64(len(v))))
+		order := make([]int, len(v))
+
+		for i := range order {
+			order[i] = i
+		}
+
+		sort.Slice(order, func(i, j int) bool {
+			return math.Abs(v[order[i]]) > math.Abs(v[order[j]])
+		})
+
+		order = order[:k]
+		sort.Ints(order)
+
+		u.Indices = make([]uint32, k)
+		values = make([]float64, k)
+
+		for i, idx := range order {
+			u.Indices[i] = uint32(idx)
+			values[i] = v[idx]
+		}
+	}
+
+	if !c.Quantize {
+		u.Values = values
+		return u
+	}
+
+	largest := 0.0
+
+	for _, x := range values {
+		largest = math.Max(largest, math.Abs(x))
+	}
+
+	u.Scale = largest / 127
+	u.Quantized = make([]byte, len(values))
+
+	for i, x := range values {
+		q := 0.0
+		if u.Scale > 0 {
+			q = math.Round(x / u.Scale)
+		}
+
+		u.Quantized[i] = byte(int(q) + 128)
+	}
+
+	return u
+}
+
+// decompress decodes the full vector of changes, with zero for anything that wasn't sent
+func (u CompressedUpdate) decompress() ([]float64, error) {
+	if u.Size < 0 {
+		return nil, errInvalidUpdate
+	}
+
+	values := u.Values
+
+	if u.Quantized != nil {
+		values = make([]float64, len(u.Quantized))
+
+		for i, q := range u.Quantized {
+			values[i] = float64(int(q)-128) * u.Scale
+		}
+	}
+
+	if u.Indices == nil {
+		if len(values) != u.Size {
+			return nil, errInvalidUpdate
+		}
+
+		return values, nil
+	}
+
+	if len(u.Indices) != len(values) {
+		return nil, errInvalidUpdate
+	}
+
+	res := make([]float64, u.Size)
+
+	for i, idx := range u.Indices {
+		if int(idx) >= u.Size {
+			return nil, errInvalidUpdate
+		}
+
+		res[idx] = values[i]
+	}
+
+	return res, nil
+}
@@ -0,0 +1,132 @@
+package nn
+
+import (
+	"archive/zip"
+	"encoding"
+	"errors"
+	"gonum.org/v1/gonum/mat"
+	"io/ioutil"
+	"sync"
+)
+
+var (
+	errUnknownLayer = errors.New("unknown layer type")
+)
+
+// Layer is implemented by user defined layers, which can be added to a network alongside its own dense layers.
+// Layers should not keep anything from Forward for Backward to use, as Backward is given the same input again, and
+// Forward must be safe for concurrent use so that the network's Calc is too. Unlike dense layers, the parameters of
+// custom layers are updated in place, so they are shared between copies of a network.
+type Layer interface {
+	// Name returns the name the layer's type was registered under with RegisterLayer
+	Name() string
+
+	// Size returns the number of outputs of the layer
+	Size() int
+
+	// Forward evaluates the layer for a column vector input
+	Forward(input mat.Matrix) mat.Matrix
+
+	// Backward takes an input and the gradient of the cost with respect to the layer's output for it, and returns the
+	// gradient with respect to the input along with the gradient of each of Params
+	Backward(input, grad mat.Matrix) (inputGrad mat.Matrix, paramGrads []mat.Matrix)
+
+	// Params returns the trainable parameters of the layer, which are updated in place during training
+	Params() []*mat.Dense
+
+	// MarshalBinary and UnmarshalBinary are used to save and load the layer along with the network
+	encoding.BinaryMarshaler
+	encoding.BinaryUnmarshaler
+}
+
+var (
+	layerTypesMu sync.RWMutex
+	layerTypes   = map[string]func() Layer{}
+)
+
+// RegisterLayer makes a Layer type available to Load. fn should return an empty layer which is then filled in using
+// its UnmarshalBinary method.
+func RegisterLayer(name string, fn func() Layer) {
+	layerTypesMu.Lock()
+	defer layerTypesMu.Unlock()
+
+	layerTypes[name] = fn
+}
+
+// newRegisteredLayer creates an empty layer of a registered type
+func newRegisteredLayer(name string) (Layer, error) {
+	layerTypesMu.RLock()
+	defer layerTypesMu.RUnlock()
+
+	fn, ok := layerTypes[name]
+	if !ok {
+		return nil, errUnknownLayer
+	}
+
+	return fn(), nil
+}
+
+// Layer adds a user defined layer reading from source and returns its index
+func (g *Graph) Layer(l Layer, source int) int {
+	g.nodes = append(g.nodes, graphNode{size: l.Size(), sources: []int{source}, custom: l})
+	return len(g.nodes) - 1
+}
+
+// NewCustomNetwork Creates a new Network made of a chain of user defined layers
+func NewCustomNetwork(inputs int, layers []Layer, learn float64) (Network, error) {
+	g := NewGraph()
+	a := g.Input(inputs)
+
+	for _, l := range layers {
+		a = g.Layer(l, a)
+	}
+
+	return g.Network([]int{a}, learn, false)
+}
+
+// loadCustomLayer reads a user defined layer from a saved network
+func loadCustomLayer(z *zip.Reader, name, path string) (Layer, error) {
+	l, err := newRegisteredLayer(name)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := z.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = f.Close()
+
+	err = l.UnmarshalBinary(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// cloneLayer makes an independent copy of a user defined layer by marshaling it, so its type must be registered
+func cloneLayer(l Layer) (Layer, error) {
+	data, err := l.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := newRegisteredLayer(l.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	err = c.UnmarshalBinary(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
@@ -0,0 +1,145 @@
+package nn
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	errInvalidWindow  = errors.New("invalid window options")
+	errInvalidOutputs = errors.New("number of output columns can't be negative")
+)
+
+// Dataset is a set of samples which can be read by index
+type Dataset interface {
+	// Len returns the number of samples
+	Len() int
+
+	// Sample returns the input and expected output of the sample at index i. expected is nil for unlabelled data.
+	Sample(i int) (input, expected []float64)
+}
+
+// Samples is a Dataset held in memory. Expected can be left empty for unlabelled data.
+type Samples struct {
+	Inputs, Expected [][]float64
+}
+
+// Len implements Dataset
+func (s Samples) Len() int {
+	return len(s.Inputs)
+}
+
+// Sample implements Dataset
+func (s Samples) Sample(i int) (input, expected []float64) {
+	if len(s.Expected) == 0 {
+		return s.Inputs[i], nil
+	}
+
+	return s.Inputs[i], s.Expected[i]
+}
+
+// ReadCSV reads samples from CSV made of numbers, with the last outputs columns of each row holding its expected
+// outputs. The first row is skipped when header is true.
+func ReadCSV(r io.Reader, outputs int, header bool) (Samples, error) {
+	if outputs < 0 {
+		return Samples{}, errInvalidOutputs
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	var (
+		s       Samples
+		columns = -1
+	)
+
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return Samples{}, err
+		}
+
+		if header && line == 1 {
+			continue
+		}
+
+		if columns == -1 {
+			columns = len(record)
+		}
+
+		if len(record) != columns {
+			return Samples{}, fmt.Errorf("line %d: %w: %d columns, expected %d", line, ErrDimensionMismatch,
+				len(record), columns)
+		}
+
+		// Every row needs at least one input column besides its outputs
+		if len(record) <= outputs {
+			return Samples{}, fmt.Errorf("line %d: %w: %d columns, expected more than %d", line,
+				ErrDimensionMismatch, len(record), outputs)
+		}
+
+		row := make([]float64, len(record))
+
+		for i, field := range record {
+			row[i], err = strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return Samples{}, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+
+		s.Inputs = append(s.Inputs, row[:len(row)-outputs:len(row)-outputs])
+
+		if outputs > 0 {
+			s.Expected = append(s.Expected, row[len(row)-outputs:])
+		}
+	}
+
+	return s, nil
+}
+
+// WindowOptions configures Windows
+type WindowOptions struct {
+	// Size is the number of consecutive values in each input
+	Size int
+
+	// Stride is the distance between the starts of neighbouring windows, 1 when zero
+	Stride int
+
+	// Horizon is how many steps after the end of a window its expected value is, 1 (the next value) when zero
+	Horizon int
+}
+
+// Windows turns a series into samples for forecasting. Each input is a window of Size consecutive values, and its
+// expected output is the single value Horizon steps after the window's last one.
+func Windows(series []float64, opts WindowOptions) (Samples, error) {
+	if opts.Stride == 0 {
+		opts.Stride = 1
+	}
+
+	if opts.Horizon == 0 {
+		opts.Horizon = 1
+	}
+
+	if opts.Size < 1 || opts.Stride < 1 || opts.Horizon < 1 {
+		return Samples{}, errInvalidWindow
+	}
+
+	var s Samples
+
+	for start := 0; start+opts.Size-1+opts.Horizon < len(series); start += opts.Stride {
+		end := start + opts.Size
+
+		s.Inputs = append(s.Inputs, series[start:end:end])
+		s.Expected = append(s.Expected, []float64{series[end-1+opts.Horizon]})
+	}
+
+	return s, nil
+}
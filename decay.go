@@ -0,0 +1,45 @@
+package nn
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// weightDecay wraps an optimizer to add L2 regularisation to every step
+type weightDecay struct {
+	Optimizer
+
+	decay  float64
+	biases bool
+}
+
+// Step implements Optimizer, adding decay times each parameter to its gradient before stepping
+func (w weightDecay) Step(n *Network, grads Gradients, rate float64) {
+	w.Optimizer.Step(n, n.decayGradients(grads, w.decay, w.biases), rate)
+}
+
+// decayGradients returns the gradients of the cost plus decay/2 times the sum of the squares of the parameters.
+// Parameters with a single column, which are biases or the scales and shifts of normalisation layers, are left out
+// unless biases is true.
+func (n Network) decayGradients(grads Gradients, decay float64, biases bool) Gradients {
+	res := make(Gradients, len(grads))
+
+	for i := range grads {
+		if grads[i] == nil {
+			continue
+		}
+
+		params := n.layers[i].params()
+		res[i] = make([]mat.Matrix, len(grads[i]))
+
+		for j, g := range grads[i] {
+			if _, c := params[j].Dims(); c == 1 && !biases {
+				res[i][j] = g
+				continue
+			}
+
+			res[i][j] = add(g, scl(decay, params[j]))
+		}
+	}
+
+	return res
+}
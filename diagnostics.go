@@ -0,0 +1,140 @@
+package nn
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"sync"
+)
+
+// NeuronDiagnostic describes a neuron whose activation is stuck over a whole dataset
+type NeuronDiagnostic struct {
+	// Layer and Neuron locate the neuron
+	Layer, Neuron int
+
+	// Min, Max and Mean describe its activation over the dataset
+	Min, Max, Mean float64
+
+	// Dead is set when the activation was always within tol of 0, and Saturated when it was always within tol of 1
+	Dead, Saturated bool
+}
+
+// StuckNeurons runs every input through the network and returns the neurons whose activations were always within tol
+// of 0 or 1. Sigmoid neurons stuck like this pass back almost no gradient, so they have stopped learning and their
+// capacity is wasted.
+func (n Network) StuckNeurons(inputs [][]float64, tol float64) []NeuronDiagnostic {
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	mins := make([][]float64, n.h)
+	maxs := make([][]float64, n.h)
+	means := make([][]float64, n.h)
+
+	for i, l := range n.layers {
+		size := l.size()
+		mins[i] = make([]float64, size)
+		maxs[i] = make([]float64, size)
+		means[i] = make([]float64, size)
+
+		for j := range mins[i] {
+			mins[i][j], maxs[i][j] = math.Inf(1), math.Inf(-1)
+		}
+	}
+
+	for _, input := range inputs {
+		if err := n.checkInput(input); err != nil {
+			panic(err)
+		}
+
+		_, activations := n.forward(split(input, n.inputs))
+
+		for i := range n.layers {
+			a := activations[len(n.inputs)+i]
+
+			for j := range mins[i] {
+				v := a.At(j, 0)
+
+				mins[i][j] = math.Min(mins[i][j], v)
+				maxs[i][j] = math.Max(maxs[i][j], v)
+				means[i][j] += v / float64(len(inputs))
+			}
+		}
+	}
+
+	var res []NeuronDiagnostic
+
+	for i := range n.layers {
+		for j := range mins[i] {
+			d := NeuronDiagnostic{
+				Layer:     i,
+				Neuron:    j,
+				Min:       mins[i][j],
+				Max:       maxs[i][j],
+				Mean:      means[i][j],
+				Dead:      math.Abs(mins[i][j]) <= tol && math.Abs(maxs[i][j]) <= tol,
+				Saturated: math.Abs(mins[i][j]-1) <= tol && math.Abs(maxs[i][j]-1) <= tol,
+			}
+
+			if d.Dead || d.Saturated {
+				res = append(res, d)
+			}
+		}
+	}
+
+	return res
+}
+
+// activationStats accumulates the activations of every layer through a hook
+type activationStats struct {
+	mu                sync.Mutex
+	count, sum, sumSq []float64
+	hooks             []Hook
+}
+
+// recordActivations starts recording the activations of every layer, until stop is called
+func (n *Network) recordActivations() *activationStats {
+	s := &activationStats{
+		count: make([]float64, n.h),
+		sum:   make([]float64, n.h),
+		sumSq: make([]float64, n.h),
+		hooks: n.hooks,
+	}
+
+	n.hooks = append(n.hooks[:len(n.hooks):len(n.hooks)], s.hook)
+
+	return s
+}
+
+// hook adds a layer's activation to the totals
+func (s *activationStats) hook(layer int, _, activation mat.Matrix) {
+	r, _ := activation.Dims()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < r; i++ {
+		v := activation.At(i, 0)
+
+		s.count[layer]++
+		s.sum[layer] += v
+		s.sumSq[layer] += v * v
+	}
+}
+
+// stop removes the hook and returns the mean and standard deviation of each layer's activations
+func (s *activationStats) stop(n *Network) (means, stds []float64) {
+	n.hooks = s.hooks
+
+	means, stds = make([]float64, len(s.count)), make([]float64, len(s.count))
+
+	for i, count := range s.count {
+		if count == 0 {
+			continue
+		}
+
+		means[i] = s.sum[i] / count
+		stds[i] = math.Sqrt(math.Max(0, s.sumSq[i]/count-means[i]*means[i]))
+	}
+
+	return means, stds
+}
@@ -0,0 +1,149 @@
+package nn
+
+import (
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+)
+
+// Distiller trains a small student network to mimic a larger teacher, compressing it for deployment. The student
+// learns from the teacher's outputs softened by a temperature, which carry more information than hard labels, mixed
+// with its usual loss on the expected outputs.
+type Distiller struct {
+	Teacher Network
+
+	// Temperature softens the outputs of both networks when comparing them, 1 when zero. Values of 2 to 5 are common.
+	// It applies to sigmoid and softmax outputs, see SetTemperature.
+	Temperature float64
+
+	// Alpha is the weight of the loss against the teacher, and 1-Alpha that of the student's loss against the expected
+	// outputs. The loss against the teacher is also scaled by the square of the temperature, which keeps its gradients
+	// the same size whatever the temperature.
+	Alpha float64
+
+	// Optimizer applies the gradients of every sample, SGD when nil
+	Optimizer Optimizer
+}
+
+// Train trains the student on every sample in order for a number of epochs, returning the average loss of each epoch.
+// expected may be nil, in which case the student only learns from the teacher whatever Alpha is. Outputs are
+// compared with KLDivergence for softmax outputs, CrossEntropy for sigmoid outputs and SquaredError for others.
+func (d Distiller) Train(student *Network, inputs, expected [][]float64, epochs int) ([]float64, error) {
+	if !sameInts(d.Teacher.inputs, student.inputs) || !sameInts(d.Teacher.outputSizes(), student.outputSizes()) {
+		return nil, fmt.Errorf("%w: teacher and student must have the same inputs and outputs",
+			ErrIncompatibleArchitectures)
+	}
+
+	alpha := d.Alpha
+
+	if expected == nil {
+		alpha = 1
+
+		for i, input := range inputs {
+			if err := student.checkInput(input); err != nil {
+				return nil, fmt.Errorf("sample %d: %w", i, err)
+			}
+		}
+	} else if err := student.checkSamples(inputs, expected); err != nil {
+		return nil, err
+	}
+
+	t := d.Temperature
+
+	if t == 0 {
+		t = 1
+	}
+
+	teacher := d.Teacher.View()
+
+	if err := teacher.SetTemperature(d.Teacher.Temperature() * t); err != nil {
+		return nil, err
+	}
+
+	opt := d.Optimizer
+	if opt == nil {
+		opt = SGD{}
+	}
+
+	losses := make([]float64, epochs)
+
+	for epoch := range losses {
+		for i, input := range inputs {
+			var label []float64
+
+			if expected != nil {
+				label = expected[i]
+			}
+
+			grads, loss := student.distillGradients(teacher.Calc(input), input, label, alpha, t)
+			losses[epoch] += loss
+
+			opt.Step(student, grads, student.learnRate)
+		}
+
+		if len(inputs) > 0 {
+			losses[epoch] /= float64(len(inputs))
+		}
+	}
+
+	return losses, nil
+}
+
+// distillGradients returns the gradients and loss of a single sample, given the teacher's softened outputs for it.
+// expected is nil when the sample has no label.
+func (n Network) distillGradients(soft, input, expected []float64, alpha, t float64) (Gradients, float64) {
+	// The student's outputs are softened by a view sharing its weights, so the gradients apply to it directly
+	view := n.View()
+	_ = view.SetTemperature(n.Temperature() * t)
+
+	zs, activations, masks := view.forwardDropout(split(input, n.inputs), true)
+	targets := split(soft, n.outputSizes())
+	outputGrads := make([]mat.Matrix, len(n.outputs))
+	loss := 0.0
+
+	for i, a := range n.outputs {
+		l := n.distillLoss(a)
+		loss += alpha * t * t * l.cost(activations[a], targets[i])
+		outputGrads[i] = scl(alpha*t*t, l.grad(activations[a], targets[i]))
+	}
+
+	grads, _ := view.backward(zs, activations, masks, outputGrads)
+
+	if expected == nil || alpha == 1 {
+		return grads, loss
+	}
+
+	loss += (1 - alpha) * n.cost(n.Calc(input), expected)
+
+	return grads.Add(n.Gradients(input, expected).Scale(1 - alpha)), loss
+}
+
+// distillLoss returns the loss used to compare an output of the student with the teacher's
+func (n Network) distillLoss(a int) Loss {
+	if a < len(n.inputs) || n.layers[a-len(n.inputs)].custom != nil {
+		return SquaredError
+	}
+
+	switch n.layers[a-len(n.inputs)].activation {
+	case Softmax:
+		return KLDivergence
+	case Sigmoid:
+		return CrossEntropy
+	}
+
+	return SquaredError
+}
+
+// sameInts reports whether two slices hold the same values
+func sameInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,239 @@
+package nn
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"sync"
+)
+
+var (
+	errMismatchedParams = errors.New("workers sent different numbers of parameters")
+	errNoBaseRound      = errors.New("compressed updates need the average of an earlier round")
+)
+
+// averagingServiceName is the name the averaging service is registered under
+const averagingServiceName = "Averaging"
+
+// AveragingServer coordinates synchronous distributed training. Every worker trains its own copy of the network on
+// its share of the data and then sends its parameters to the server, which waits until it has heard from all of them
+// and sends each the average. A worker that disconnects part way through leaves the others waiting, so runs should be
+// restarted if one fails.
+type AveragingServer struct {
+	workers int
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	round   int
+	sum     []float64
+	count   int
+	pending error
+
+	// avg and err are the result of the last round, which can't change until every worker has read them
+	avg []float64
+	err error
+}
+
+// NewAveragingServer Creates a server for a fixed number of workers
+func NewAveragingServer(workers int) *AveragingServer {
+	s := &AveragingServer{workers: workers}
+	s.cond = sync.NewCond(&s.mu)
+
+	return s
+}
+
+// Serve accepts connections from workers on l until it is closed
+func (s *AveragingServer) Serve(l net.Listener) error {
+	return serveRPC(l, averagingServiceName, &averagingService{s: s})
+}
+
+// average adds one worker's parameters to the current round and waits for the round's average
+func (s *AveragingServer) average(params []float64) ([]float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	round := s.round
+
+	if s.count == 0 {
+		s.sum = make([]float64, len(params))
+		s.pending = nil
+	}
+
+	if len(params) != len(s.sum) {
+		s.pending = errMismatchedParams
+	} else {
+		for i, v := range params {
+			s.sum[i] += v
+		}
+	}
+
+	s.count++
+
+	if s.count == s.workers {
+		s.avg, s.err = s.sum, s.pending
+
+		for i := range s.avg {
+			s.avg[i] /= float64(s.workers)
+		}
+
+		s.count = 0
+		s.round++
+		s.cond.Broadcast()
+	}
+
+	for s.round == round {
+		s.cond.Wait()
+	}
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return s.avg, nil
+}
+
+// averagingService exposes the server over net/rpc
+type averagingService struct {
+	s *AveragingServer
+}
+
+// Average is the RPC method workers call with their parameters
+func (a *averagingService) Average(params []float64, reply *[]float64) error {
+	avg, err := a.s.average(params)
+	if err != nil {
+		return err
+	}
+
+	*reply = avg
+	return nil
+}
+
+// serveRPC serves a single net/rpc service on every connection accepted from l
+func serveRPC(l net.Listener, name string, service interface{}) error {
+	server := rpc.NewServer()
+
+	err := server.RegisterName(name, service)
+	if err != nil {
+		return err
+	}
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+
+		go server.ServeConn(conn)
+	}
+}
+
+// AverageCompressed is the RPC method workers call with the compressed change they made since the last average
+func (a *averagingService) AverageCompressed(u CompressedUpdate, reply *[]float64) error {
+	a.s.mu.Lock()
+	base := a.s.avg
+	a.s.mu.Unlock()
+
+	if base == nil {
+		return errNoBaseRound
+	}
+
+	// The size comes from the worker, so it is checked before anything that large is allocated
+	if u.Size != len(base) {
+		return errMismatchedParams
+	}
+
+	params, err := u.decompress()
+	if err != nil {
+		return err
+	}
+
+	for i := range params {
+		params[i] += base[i]
+	}
+
+	return a.Average(params, reply)
+}
+
+// AveragingClient is a worker's connection to an AveragingServer
+type AveragingClient struct {
+	// Compression compresses what the client sends after its first sync, which always sends every parameter. The
+	// part of each change left out by compression is carried over to the next update, so nothing is lost for good.
+	Compression Compression
+
+	c        *rpc.Client
+	base     []float64
+	residual []float64
+}
+
+// DialAveraging connects to an AveragingServer
+func DialAveraging(addr string) (*AveragingClient, error) {
+	c, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AveragingClient{c: c}, nil
+}
+
+// Sync sends the network's parameters to the server and replaces them with the average over every worker. It blocks
+// until every worker has called it.
+func (c *AveragingClient) Sync(n *Network) error {
+	var (
+		avg    []float64
+		params = n.Params()
+		err    error
+	)
+
+	if c.base == nil || (c.Compression == Compression{}) {
+		err = c.c.Call(averagingServiceName+".Average", params, &avg)
+	} else {
+		err = c.c.Call(averagingServiceName+".AverageCompressed", c.compress(params), &avg)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	c.base = append([]float64(nil), avg...)
+
+	return n.SetParams(avg)
+}
+
+// compress encodes the change since the last sync, keeping whatever compression leaves out for next time
+func (c *AveragingClient) compress(params []float64) CompressedUpdate {
+	if len(c.residual) != len(params) {
+		c.residual = make([]float64, len(params))
+	}
+
+	delta := make([]float64, len(params))
+
+	for i := range delta {
+		delta[i] = params[i] - c.base[i] + c.residual[i]
+	}
+
+	u := c.Compression.compress(delta)
+	sent, _ := u.decompress()
+
+	for i := range delta {
+		c.residual[i] = delta[i] - sent[i]
+	}
+
+	return u
+}
+
+// SyncEvery returns a callback for TrainWith which syncs the network every given number of epochs. Every worker must
+// train for the same number of epochs.
+func (c *AveragingClient) SyncEvery(epochs int) Callback {
+	return func(n *Network, e Epoch) error {
+		if epochs > 0 && e.Epoch%epochs != 0 {
+			return nil
+		}
+
+		return c.Sync(n)
+	}
+}
+
+// Close closes the connection to the server
+func (c *AveragingClient) Close() error {
+	return c.c.Close()
+}
@@ -0,0 +1,190 @@
+package nn
+
+import (
+	"math"
+	"math/rand"
+)
+
+// dpOrders are the Rényi orders the privacy accountant tracks
+var dpOrders = func() []float64 {
+	var orders []float64
+
+	for a := 2; a <= 256; a++ {
+		orders = append(orders, float64(a))
+	}
+
+	return orders
+}()
+
+// DPSGD trains with differentially private SGD. Every step samples a batch by including each example independently
+// with probability BatchSize/len(inputs), clips the gradient of every example to a norm of at most Clip, and adds
+// Gaussian noise with a standard deviation of NoiseMultiplier*Clip to their sum. The privacy spent so far is tracked
+// with a Rényi differential privacy accountant and reported by Epsilon.
+type DPSGD struct {
+	// Clip bounds the norm of each example's gradient
+	Clip float64
+
+	// NoiseMultiplier is the ratio of the noise's standard deviation to Clip. Larger values give more privacy.
+	NoiseMultiplier float64
+
+	// BatchSize is the expected number of examples in each step
+	BatchSize int
+
+	// rdp is the privacy spent at each order in dpOrders
+	rdp []float64
+}
+
+// NewDPSGD Creates a differentially private trainer
+func NewDPSGD(clip, noise float64, batch int) *DPSGD {
+	return &DPSGD{Clip: clip, NoiseMultiplier: noise, BatchSize: batch, rdp: make([]float64, len(dpOrders))}
+}
+
+// Train trains the network for a number of epochs, each made of len(inputs)/BatchSize steps
+func (d *DPSGD) Train(n *Network, inputs, expected [][]float64, epochs int) error {
+	if len(inputs) != len(expected) || len(inputs) == 0 || d.BatchSize < 1 || d.BatchSize > len(inputs) {
+		return ErrDimensionMismatch
+	}
+
+	if err := n.checkSamples(inputs, expected); err != nil {
+		return err
+	}
+
+	q := float64(d.BatchSize) / float64(len(inputs))
+	steps := len(inputs) / d.BatchSize
+
+	for epoch := 0; epoch < epochs; epoch++ {
+		for step := 0; step < steps; step++ {
+			var sum Gradients
+
+			for i := range inputs {
+				if rand.Float64() >= q {
+					continue
+				}
+
+				grads := n.Gradients(inputs[i], expected[i])
+
+				if norm := grads.Norm(); norm > d.Clip {
+					grads = grads.Scale(d.Clip / norm)
+				}
+
+				if sum == nil {
+					sum = grads
+				} else {
+					sum = sum.Add(grads)
+				}
+			}
+
+			if sum == nil {
+				sum = n.zeroGradients()
+			}
+
+			noisy := sum.Add(n.noise(d.NoiseMultiplier * d.Clip))
+			n.ApplyGradients(noisy.Scale(1/float64(d.BatchSize)), n.learnRate)
+
+			d.account(q)
+		}
+	}
+
+	return nil
+}
+
+// zeroGradients returns gradients of zero for every parameter
+func (n Network) zeroGradients() Gradients {
+	res := make(Gradients, n.h)
+
+	for i, l := range n.layers {
+		for _, p := range l.params() {
+			res[i] = append(res[i], scl(0, p))
+		}
+	}
+
+	return res
+}
+
+// noise returns Gaussian noise with the given standard deviation for every parameter
+func (n Network) noise(std float64) Gradients {
+	res := make(Gradients, n.h)
+
+	for i, l := range n.layers {
+		for _, p := range l.params() {
+			res[i] = append(res[i], fun(func(_, _ int, _ float64) float64 {
+				return rand.NormFloat64() * std
+			}, p))
+		}
+	}
+
+	return res
+}
+
+// account adds the privacy spent by one step of the sampled Gaussian mechanism with sampling rate q
+func (d *DPSGD) account(q float64) {
+	if d.rdp == nil {
+		d.rdp = make([]float64, len(dpOrders))
+	}
+
+	for i, a := range dpOrders {
+		d.rdp[i] += sampledGaussianRDP(q, d.NoiseMultiplier, a)
+	}
+}
+
+// Epsilon returns the ε of the (ε, δ) differential privacy guarantee of the training done so far
+func (d *DPSGD) Epsilon(delta float64) float64 {
+	if d.rdp == nil {
+		return 0
+	}
+
+	eps := math.Inf(1)
+
+	for i, a := range dpOrders {
+		eps = math.Min(eps, d.rdp[i]+math.Log(1/delta)/(a-1))
+	}
+
+	return eps
+}
+
+// sampledGaussianRDP is the Rényi differential privacy of one step of the Gaussian mechanism with noise multiplier
+// sigma applied to a batch sampled with rate q, at an integer order a. It uses the exact binomial expansion from
+// Mironov et al., "Rényi Differential Privacy of the Sampled Gaussian Mechanism", worked out in log space.
+func sampledGaussianRDP(q, sigma, a float64) float64 {
+	if q == 0 {
+		return 0
+	}
+
+	if sigma == 0 {
+		return math.Inf(1)
+	}
+
+	if q == 1 {
+		return a / (2 * sigma * sigma)
+	}
+
+	logA := math.Inf(-1)
+
+	for k := 0.0; k <= a; k++ {
+		lgA, _ := math.Lgamma(a + 1)
+		lgK, _ := math.Lgamma(k + 1)
+		lgAK, _ := math.Lgamma(a - k + 1)
+
+		term := lgA - lgK - lgAK + k*math.Log(q) + (a-k)*math.Log(1-q) + (k*k-k)/(2*sigma*sigma)
+		logA = logAddExp(logA, term)
+	}
+
+	return logA / (a - 1)
+}
+
+// logAddExp returns log(exp(a) + exp(b)) without overflowing
+func logAddExp(a, b float64) float64 {
+	if math.IsInf(a, -1) {
+		return b
+	}
+
+	if math.IsInf(b, -1) {
+		return a
+	}
+
+	if a < b {
+		a, b = b, a
+	}
+
+	return a + math.Log1p(math.Exp(b-a))
+}
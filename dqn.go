@@ -0,0 +1,88 @@
+package nn
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"math/rand"
+)
+
+// Transition is a single step of experience for off-policy learning
+type Transition struct {
+	State  []float64
+	Action int
+	Reward float64
+	Next   []float64
+	Done   bool
+}
+
+// ReplayBuffer keeps the most recent transitions up to a fixed capacity, discarding the oldest once it is full. It
+// is not safe for concurrent use.
+type ReplayBuffer struct {
+	items []Transition
+	next  int
+}
+
+// NewReplayBuffer Creates an empty replay buffer holding up to capacity transitions
+func NewReplayBuffer(capacity int) *ReplayBuffer {
+	return &ReplayBuffer{items: make([]Transition, 0, capacity)}
+}
+
+// Add stores a transition, replacing the oldest one if the buffer is full
+func (b *ReplayBuffer) Add(t Transition) {
+	if len(b.items) < cap(b.items) {
+		b.items = append(b.items, t)
+		return
+	}
+
+	if len(b.items) == 0 {
+		return
+	}
+
+	b.items[b.next] = t
+	b.next = (b.next + 1) % len(b.items)
+}
+
+// Len returns the number of transitions stored
+func (b *ReplayBuffer) Len() int {
+	return len(b.items)
+}
+
+// Sample picks k transitions uniformly at random, with replacement. It returns nothing if the buffer is empty.
+func (b *ReplayBuffer) Sample(k int) []Transition {
+	if len(b.items) == 0 || k <= 0 {
+		return nil
+	}
+
+	res := make([]Transition, k)
+
+	for i := range res {
+		res[i] = b.items[rand.Intn(len(b.items))]
+	}
+
+	return res
+}
+
+// SyncFrom moves the parameters of the network towards those of other, which must have the same architecture. A tau
+// of 1 copies them outright, as for the periodic target network updates of DQN, while a small tau gives the soft
+// updates p = tau*other + (1-tau)*p.
+func (n *Network) SyncFrom(other *Network, tau float64) error {
+	if !n.sameShape(*other) {
+		return ErrIncompatibleArchitectures
+	}
+
+	for i := range n.layers {
+		params := n.layers[i].params()
+
+		for j, p := range other.layers[i].params() {
+			if tau == 1 {
+				params[j] = mat.DenseCopyOf(p)
+				continue
+			}
+
+			params[j] = add(scl(tau, p), scl(1-tau, params[j]))
+		}
+
+		n.layers[i].setParams(params)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package nn
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/mat"
+	"math/rand"
+)
+
+var (
+	errInvalidDropout = errors.New("invalid dropout rate")
+	errInvalidSamples = errors.New("invalid number of samples")
+)
+
+// SetDropout sets the fraction of a layer's outputs that are randomly zeroed during training, which stops the layers
+// after it relying on any few of them. The outputs that are kept are scaled up to make up for the dropped ones, so
+// Calc uses every output unchanged. Layers are numbered from 0, and a rate of 0 turns dropout off.
+func (n *Network) SetDropout(layer int, rate float64) error {
+	if layer < 0 || layer >= n.h || rate < 0 || rate >= 1 {
+		return errInvalidDropout
+	}
+
+	n.layers[layer].dropout = rate
+	return nil
+}
+
+// dropoutMask returns a column vector which zeroes values with probability rate and scales the rest by 1/(1-rate)
+func dropoutMask(size int, rate float64) mat.Matrix {
+	data := make([]float64, size)
+
+	for i := range data {
+		if rand.Float64() >= rate {
+			data[i] = 1 / (1 - rate)
+		}
+	}
+
+	return mat.NewDense(size, 1, data)
+}
+
+// CalcWithUncertainty evaluates an input samples times with dropout left on, and returns the mean and variance of
+// each output over those passes (Monte Carlo dropout). The variance estimates how uncertain the network is about the
+// input, and is always zero for networks without dropout.
+func (n Network) CalcWithUncertainty(data []float64, samples int) (mean, variance []float64) {
+	if err := n.checkInput(data); err != nil {
+		panic(err)
+	}
+
+	if samples < 1 {
+		panic(errInvalidSamples)
+	}
+
+	mean, variance = make([]float64, n.o), make([]float64, n.o)
+	inputs := split(data, n.inputs)
+
+	// Welford's algorithm keeps the variance accurate when it is small compared to the mean
+	for s := 1; s <= samples; s++ {
+		_, activations, _ := n.forwardDropout(inputs, true)
+
+		for i, v := range n.outputValues(activations) {
+			delta := v - mean[i]
+			mean[i] += delta / float64(s)
+			variance[i] += delta * (v - mean[i])
+		}
+	}
+
+	for i := range variance {
+		variance[i] /= float64(samples)
+	}
+
+	return mean, variance
+}
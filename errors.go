@@ -0,0 +1,46 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The errors below are returned, usually wrapped with more detail, so that callers can tell failures apart with
+// errors.Is. Errors about data of the wrong size can also be inspected with errors.As and a DimensionError.
+var (
+	// ErrDimensionMismatch is returned, or panicked with, when data isn't the size the network expects
+	ErrDimensionMismatch = errors.New("dimension mismatch")
+
+	// ErrIncompatibleArchitectures is returned when networks that must share an architecture don't
+	ErrIncompatibleArchitectures = errors.New("networks have different architectures")
+
+	// ErrCorruptModel is returned when a saved network can't be read because its contents are invalid
+	ErrCorruptModel = errors.New("corrupt model")
+
+	// ErrUnsupportedVersion is returned when a saved network was written by a newer version of the package
+	ErrUnsupportedVersion = errors.New("unsupported model version")
+)
+
+// formatVersion is the version of the format written by Save and SaveArchitecture. Files saved before versions were
+// recorded have a version of 0.
+const formatVersion = 1
+
+// checkVersion returns ErrUnsupportedVersion if a saved network is too new to be read
+func checkVersion(version int) error {
+	if version > formatVersion {
+		return fmt.Errorf("%w: version %d, newest supported is %d", ErrUnsupportedVersion, version, formatVersion)
+	}
+
+	return nil
+}
+
+// corrupt wraps an error found while reading a saved network with ErrCorruptModel, unless it is already one of the
+// package's errors about saved networks
+func corrupt(err error) error {
+	if err == nil || errors.Is(err, ErrCorruptModel) || errors.Is(err, ErrUnsupportedVersion) ||
+		errors.Is(err, errUnknownLayer) || errors.Is(err, errCustomArchitecture) {
+		return err
+	}
+
+	return fmt.Errorf("%w: %v", ErrCorruptModel, err)
+}
@@ -0,0 +1,163 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+)
+
+var (
+	errNoTaskData = errors.New("no samples to consolidate")
+)
+
+// EWC implements elastic weight consolidation for learning tasks one after another. After training on a task, call
+// Consolidate with its data to estimate how important every parameter was to it, then set TrainOptions.EWC when
+// training on the next task. Moving important parameters away from the values they had is then penalised, so the
+// network doesn't forget earlier tasks while it learns new ones.
+type EWC struct {
+	// Lambda scales the penalty, trading remembering earlier tasks against learning the current one
+	Lambda float64
+
+	tasks []ewcTask
+}
+
+// ewcTask holds the importance of every parameter to a consolidated task and the values they had after it, in the
+// same layout as Gradients
+type ewcTask struct {
+	fisher, anchor [][]*mat.Dense
+}
+
+// NewEWC Creates an EWC penalty with the given strength and no consolidated tasks
+func NewEWC(lambda float64) *EWC {
+	return &EWC{Lambda: lambda}
+}
+
+// Consolidate records the network's current parameters and their importance to the task the samples come from, which
+// is estimated by the diagonal of the empirical Fisher information: the average square of each parameter's gradient.
+// Every consolidated task is remembered.
+func (e *EWC) Consolidate(n Network, inputs, expected [][]float64) error {
+	if len(inputs) == 0 {
+		return errNoTaskData
+	}
+
+	if err := n.checkSamples(inputs, expected); err != nil {
+		return err
+	}
+
+	if len(e.tasks) > 0 && !e.fits(n) {
+		return fmt.Errorf("%w: the network has changed since the last task was consolidated",
+			ErrIncompatibleArchitectures)
+	}
+
+	t := ewcTask{fisher: make([][]*mat.Dense, n.h), anchor: n.copyParams()}
+
+	for i, p := range t.anchor {
+		for _, m := range p {
+			r, c := m.Dims()
+			t.fisher[i] = append(t.fisher[i], mat.NewDense(r, c, nil))
+		}
+	}
+
+	for s := range inputs {
+		grads := n.Gradients(inputs[s], expected[s])
+
+		for i := range grads {
+			for j, g := range grads[i] {
+				t.fisher[i][j].Add(t.fisher[i][j], mul(g, g))
+			}
+		}
+	}
+
+	for i := range t.fisher {
+		for _, f := range t.fisher[i] {
+			f.Scale(1/float64(len(inputs)), f)
+		}
+	}
+
+	e.tasks = append(e.tasks, t)
+
+	return nil
+}
+
+// Tasks returns the number of consolidated tasks
+func (e *EWC) Tasks() int {
+	return len(e.tasks)
+}
+
+// Penalty returns the penalty for the network's current parameters: Lambda/2 times the sum over every consolidated
+// task of each parameter's importance times the square of how far it has moved
+func (e *EWC) Penalty(n Network) float64 {
+	if !e.fits(n) {
+		panic(ErrIncompatibleArchitectures)
+	}
+
+	total := 0.0
+
+	for _, t := range e.tasks {
+		for i, l := range n.layers {
+			for j, p := range l.params() {
+				d := sub(p, t.anchor[i][j])
+				total += mat.Sum(mul(t.fisher[i][j], mul(d, d)))
+			}
+		}
+	}
+
+	return e.Lambda / 2 * total
+}
+
+// fits reports whether the consolidated tasks have the same layout of parameters as the network
+func (e *EWC) fits(n Network) bool {
+	for _, t := range e.tasks {
+		if len(t.anchor) != len(n.layers) {
+			return false
+		}
+
+		for i, l := range n.layers {
+			params := l.params()
+
+			if len(params) != len(t.anchor[i]) {
+				return false
+			}
+
+			for j, p := range params {
+				if !sameDims(p, t.anchor[i][j]) {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// ewcPenalty wraps an optimizer, adding the gradient of an EWC penalty to the gradients of every step
+type ewcPenalty struct {
+	Optimizer
+
+	ewc *EWC
+}
+
+// Step implements Optimizer, adding Lambda times each parameter's importance times how far it has moved to its
+// gradient before stepping
+func (w ewcPenalty) Step(n *Network, grads Gradients, rate float64) {
+	res := make(Gradients, len(grads))
+
+	for i := range grads {
+		if grads[i] == nil {
+			continue
+		}
+
+		params := n.layers[i].params()
+		res[i] = make([]mat.Matrix, len(grads[i]))
+
+		for j, g := range grads[i] {
+			res[i][j] = g
+
+			for _, t := range w.ewc.tasks {
+				res[i][j] = add(res[i][j], scl(w.ewc.Lambda, mul(t.fisher[i][j], sub(params[j], t.anchor[i][j]))))
+			}
+		}
+	}
+
+	w.Optimizer.Step(n, res, rate)
+}
@@ -0,0 +1,205 @@
+package nn
+
+import (
+	"errors"
+	"net"
+	"net/rpc"
+	"sync"
+)
+
+var (
+	errStaleUpdate     = errors.New("update is for an earlier round")
+	errNoUpdateSamples = errors.New("update must be trained on at least one sample")
+	errUpdateSize      = errors.New("update doesn't have a change for every parameter of the model")
+)
+
+// federatedServiceName is the name the federated service is registered under
+const federatedServiceName = "Federated"
+
+// FederatedModel is the global model handed to clients at the start of a round
+type FederatedModel struct {
+	Round  int
+	Params []float64
+}
+
+// FederatedUpdate is the change a client made to the global model by training on its own data
+type FederatedUpdate struct {
+	Round   int
+	Delta   []float64
+	Samples int
+}
+
+// FederatedServer runs federated averaging. Clients fetch the global model, train it on their own data and submit
+// the change they made, and once enough updates have arrived for a round the server moves the global model by their
+// average, weighted by how many samples each client trained on. Clients never share their data, only their updates.
+type FederatedServer struct {
+	clients int
+
+	mu      sync.Mutex
+	global  Network
+	round   int
+	sum     []float64
+	samples int
+	count   int
+}
+
+// NewFederatedServer Creates a server starting from the given network, which finishes a round once it has updates
+// from the given number of clients
+func NewFederatedServer(n Network, clients int) *FederatedServer {
+	return &FederatedServer{clients: clients, global: n.Copy()}
+}
+
+// Serve accepts connections from clients on l until it is closed
+func (s *FederatedServer) Serve(l net.Listener) error {
+	return serveRPC(l, federatedServiceName, &federatedService{s: s})
+}
+
+// Network returns a copy of the current global model
+func (s *FederatedServer) Network() Network {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.global.Copy()
+}
+
+// Round returns the number of rounds finished so far
+func (s *FederatedServer) Round() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.round
+}
+
+// fetch returns the current global model
+func (s *FederatedServer) fetch() FederatedModel {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return FederatedModel{Round: s.round, Params: s.global.Params()}
+}
+
+// submit adds a client's update to the current round, finishing the round if it was the last one needed
+func (s *FederatedServer) submit(u FederatedUpdate) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if u.Round != s.round {
+		return errStaleUpdate
+	}
+
+	if u.Samples <= 0 {
+		return errNoUpdateSamples
+	}
+
+	params := s.global.Params()
+
+	if len(u.Delta) != len(params) {
+		return errUpdateSize
+	}
+
+	if s.count == 0 {
+		s.sum = make([]float64, len(params))
+		s.samples = 0
+	}
+
+	for i, d := range u.Delta {
+		s.sum[i] += d * float64(u.Samples)
+	}
+
+	s.samples += u.Samples
+	s.count++
+
+	if s.count < s.clients {
+		return nil
+	}
+
+	for i := range params {
+		params[i] += s.sum[i] / float64(s.samples)
+	}
+
+	// The round starts over whether or not the update could be applied, so that it can't get stuck
+	err := s.global.SetParams(params)
+	s.count, s.sum, s.samples = 0, nil, 0
+
+	if err != nil {
+		return err
+	}
+
+	s.round++
+
+	return nil
+}
+
+// federatedService exposes the server over net/rpc
+type federatedService struct {
+	s *FederatedServer
+}
+
+// Fetch is the RPC method clients call to get the global model
+func (f *federatedService) Fetch(_ int, reply *FederatedModel) error {
+	*reply = f.s.fetch()
+	return nil
+}
+
+// Submit is the RPC method clients call with their updates
+func (f *federatedService) Submit(u FederatedUpdate, _ *int) error {
+	return f.s.submit(u)
+}
+
+// FederatedClient is a client's connection to a FederatedServer
+type FederatedClient struct {
+	c *rpc.Client
+}
+
+// DialFederated connects to a FederatedServer
+func DialFederated(addr string) (*FederatedClient, error) {
+	c, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FederatedClient{c: c}, nil
+}
+
+// Train takes part in a round: it loads the global model into n, trains it on the client's own data and submits the
+// change. Afterwards n holds the locally trained model, which can be kept as a personalised version of the global
+// one. An update is rejected if the round finished while the client was training.
+func (c *FederatedClient) Train(n *Network, inputs, expected [][]float64, opts TrainOptions) (Report, error) {
+	var global FederatedModel
+
+	err := c.c.Call(federatedServiceName+".Fetch", 0, &global)
+	if err != nil {
+		return Report{}, err
+	}
+
+	err = n.SetParams(global.Params)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report, err := n.TrainWith(inputs, expected, opts)
+	if err != nil {
+		return report, err
+	}
+
+	delta := n.Params()
+
+	for i := range delta {
+		delta[i] -= global.Params[i]
+	}
+
+	var ack int
+
+	err = c.c.Call(federatedServiceName+".Submit", FederatedUpdate{
+		Round:   global.Round,
+		Delta:   delta,
+		Samples: len(inputs),
+	}, &ack)
+
+	return report, err
+}
+
+// Close closes the connection to the server
+func (c *FederatedClient) Close() error {
+	return c.c.Close()
+}
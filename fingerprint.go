@@ -0,0 +1,104 @@
+package nn
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"hash"
+	"math"
+)
+
+// Fingerprint returns a SHA-256 hash of the network's architecture and weights as a hex string. Networks with the same
+// layout, activations, heads, dropout rates, weight ties, temperature and exactly the same weights always have the same
+// fingerprint, so it can be used to check which model a server has loaded. The learning rate and hooks don't change
+// what the network calculates, so they are not included.
+func (n Network) Fingerprint() string {
+	h := sha256.New()
+
+	writeInts(h, n.i, n.o, len(n.inputs), len(n.outputs), len(n.layers))
+	writeInts(h, n.inputs...)
+	writeFloats(h, n.Temperature())
+
+	for i, head := range n.Heads() {
+		writeInts(h, n.outputs[i], head.Size)
+		writeString(h, head.Loss.Name)
+		writeFloats(h, head.Weight)
+		writeActivation(h, head.Activation)
+	}
+
+	for _, l := range n.layers {
+		writeInts(h, l.from, l.size(), len(l.skips), boolInt(l.biases != nil))
+		writeActivation(h, l.activation)
+		writeFloats(h, l.dropout)
+
+		if l.tie != nil {
+			writeInts(h, 1, l.tie.source, boolInt(l.tie.transpose))
+		} else {
+			writeInts(h, 0, 0, 0)
+		}
+
+		if l.custom != nil {
+			writeString(h, l.custom.Name())
+		} else {
+			writeString(h, "")
+		}
+
+		for _, s := range l.skips {
+			writeInts(h, s.from, boolInt(s.weights != nil))
+		}
+
+		for _, p := range l.params() {
+			r, c := p.Dims()
+			writeInts(h, r, c)
+
+			for y := 0; y < r; y++ {
+				for x := 0; x < c; x++ {
+					writeFloats(h, p.At(y, x))
+				}
+			}
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeActivation hashes an activation along with its parameter
+func writeActivation(h hash.Hash, a Activation) {
+	writeString(h, a.Name)
+	writeFloats(h, a.Alpha)
+}
+
+// boolInt returns 1 for true and 0 for false
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// writeInts hashes integers as fixed size little endian values
+func writeInts(h hash.Hash, values ...int) {
+	var buf [8]byte
+
+	for _, v := range values {
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		_, _ = h.Write(buf[:])
+	}
+}
+
+// writeFloats hashes the exact bits of floats
+func writeFloats(h hash.Hash, values ...float64) {
+	var buf [8]byte
+
+	for _, v := range values {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+		_, _ = h.Write(buf[:])
+	}
+}
+
+// writeString hashes a length prefixed string
+func writeString(h hash.Hash, s string) {
+	writeInts(h, len(s))
+	_, _ = h.Write([]byte(s))
+}
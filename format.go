@@ -0,0 +1,157 @@
+package nn
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var (
+	errUnknownFormat = errors.New("unknown model format")
+)
+
+// sniffSize is the number of bytes at the start of a file that formats are recognised from
+const sniffSize = 16
+
+// format is a way of storing networks which Load can recognise from the start of a file
+type format struct {
+	name  string
+	match func(header []byte) bool
+	load  func(r io.ReaderAt, size int64) (Network, error)
+}
+
+// formats are tried by Load in order. Safetensors, NPZ and ONNX files aren't among them, as the first two only hold
+// weights and there is no ONNX reader.
+var formats = []format{
+	{name: "zip", match: isZip, load: loadZipReader},
+	{name: "json", match: isJSON, load: loadArchitectureReader},
+	{name: "protobuf", match: isProto, load: loadProtoReader},
+}
+
+// Load will open a saved network, working out which format it was saved in from its contents. It reads archives saved
+// by Save, the flat binary format written by MarshalProto, and architectures saved by SaveArchitecture, which are
+// given random weights like LoadArchitecture does. Networks saved with SaveSharded are loaded from their manifest,
+// with the shards alongside it.
+func Load(filename string) (Network, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return Network{}, err
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return Network{}, err
+	}
+
+	header, err := sniff(f)
+	if err != nil {
+		return Network{}, err
+	}
+
+	// Shard manifests point to files next to them, so they can only be loaded from a file
+	if isJSON(header) {
+		data, err := ioutil.ReadAll(io.NewSectionReader(f, 0, info.Size()))
+		if err != nil {
+			return Network{}, err
+		}
+
+		if m, ok := parseShardManifest(data); ok {
+			data, err = m.join(filepath.Dir(filename))
+			if err != nil {
+				return Network{}, err
+			}
+
+			return LoadBytes(data)
+		}
+	}
+
+	return loadReader(f, info.Size(), header)
+}
+
+// LoadBytes reads a saved network from memory, working out which format it was saved in from its contents
+func LoadBytes(data []byte) (Network, error) {
+	r := bytes.NewReader(data)
+
+	header, err := sniff(r)
+	if err != nil {
+		return Network{}, err
+	}
+
+	return loadReader(r, int64(len(data)), header)
+}
+
+// sniff reads the first bytes of a saved network, or all of it if it is shorter
+func sniff(r io.ReaderAt) ([]byte, error) {
+	header := make([]byte, sniffSize)
+
+	k, err := r.ReadAt(header, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return header[:k], nil
+}
+
+// loadReader reads a network in the first format that matches its header
+func loadReader(r io.ReaderAt, size int64, header []byte) (Network, error) {
+	for _, f := range formats {
+		if f.match(header) {
+			n, err := f.load(r, size)
+			return n, corrupt(err)
+		}
+	}
+
+	return Network{}, errUnknownFormat
+}
+
+// isZip checks for the signature of a zip archive
+func isZip(header []byte) bool {
+	return bytes.HasPrefix(header, []byte("PK\x03\x04")) || bytes.HasPrefix(header, []byte("PK\x05\x06"))
+}
+
+// isJSON checks for a JSON object
+func isJSON(header []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(header), []byte("{"))
+}
+
+// isProto checks for a Network message written by MarshalProto, which always starts with its packed inputs field. The
+// first byte is the key of that field: field number 1 with the length-delimited wire type, 2.
+func isProto(header []byte) bool {
+	return len(header) > 0 && header[0] == 1<<3|2
+}
+
+// loadZipReader reads a network saved by Save
+func loadZipReader(r io.ReaderAt, size int64) (Network, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return Network{}, err
+	}
+
+	return loadZip(z)
+}
+
+// loadArchitectureReader builds a network with random weights from an architecture saved by SaveArchitecture
+func loadArchitectureReader(r io.ReaderAt, size int64) (Network, error) {
+	data, err := ioutil.ReadAll(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return Network{}, err
+	}
+
+	return parseArchitecture(data, nil)
+}
+
+// loadProtoReader reads a network written by MarshalProto
+func loadProtoReader(r io.ReaderAt, size int64) (Network, error) {
+	data, err := ioutil.ReadAll(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return Network{}, err
+	}
+
+	return UnmarshalProto(data)
+}
@@ -0,0 +1,209 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+)
+
+var (
+	errGANShape      = errors.New("the generator's outputs must match the discriminator's inputs, which has one output")
+	errInvalidGANRun = errors.New("steps and batch size must be positive")
+)
+
+// GAN trains a generator network to produce samples a discriminator network can't tell apart from real ones. The
+// discriminator has a single output, trained towards 1 for real samples and 0 for generated ones with its own head's
+// loss, so it should usually be a sigmoid with CrossEntropy. The generator is trained with the non-saturating loss,
+// pushing the discriminator's output on its samples towards 1.
+type GAN struct {
+	Generator, Discriminator *Network
+
+	// DiscriminatorSteps is the number of discriminator updates made before every generator update, 1 when zero
+	DiscriminatorSteps int
+
+	// RealLabel is the target for real samples, 1 when zero. Values like 0.9 (one-sided label smoothing) stop the
+	// discriminator from becoming overconfident.
+	RealLabel float64
+
+	// FlipLabels is the probability of swapping the targets of real and generated samples in each discriminator
+	// update, which also keeps the discriminator from winning too easily
+	FlipLabels float64
+
+	// Noise draws the inputs of the generator, standard normal values when nil
+	Noise func(size int) []float64
+
+	// GeneratorOptimizer and DiscriminatorOptimizer apply the gradients of each network, SGD when nil
+	GeneratorOptimizer, DiscriminatorOptimizer Optimizer
+}
+
+// GANStep holds the losses of one training step, each averaged over the batch
+type GANStep struct {
+	// DiscriminatorLoss is the loss of the discriminator's last update, over both real and generated samples
+	DiscriminatorLoss float64 `json:"discriminator_loss"`
+
+	// GeneratorLoss is the loss of the generator's update
+	GeneratorLoss float64 `json:"generator_loss"`
+}
+
+// check returns an error if the networks can't be trained against each other
+func (g GAN) check() error {
+	noise, generated := g.Generator.Dims()
+	inputs, outputs := g.Discriminator.Dims()
+
+	if noise < 1 || generated != inputs || outputs != 1 {
+		return errGANShape
+	}
+
+	return nil
+}
+
+// Generate returns a sample made by the generator from fresh noise
+func (g GAN) Generate() []float64 {
+	return g.Generator.Calc(g.noise())
+}
+
+// noise draws an input for the generator
+func (g GAN) noise() []float64 {
+	size, _ := g.Generator.Dims()
+
+	if g.Noise != nil {
+		return g.Noise(size)
+	}
+
+	z := make([]float64, size)
+
+	for i := range z {
+		z[i] = rand.NormFloat64()
+	}
+
+	return z
+}
+
+// Train makes a number of training steps, each using batch real samples drawn at random, and returns the losses of
+// every step
+func (g *GAN) Train(real [][]float64, steps, batch int) ([]GANStep, error) {
+	if steps < 1 || batch < 1 {
+		return nil, errInvalidGANRun
+	}
+
+	if len(real) == 0 {
+		return nil, ErrDimensionMismatch
+	}
+
+	res := make([]GANStep, steps)
+
+	for s := range res {
+		samples := make([][]float64, batch)
+
+		for i := range samples {
+			samples[i] = real[rand.Intn(len(real))]
+		}
+
+		var err error
+
+		res[s], err = g.Step(samples)
+		if err != nil {
+			return res[:s], err
+		}
+	}
+
+	return res, nil
+}
+
+// Step makes DiscriminatorSteps discriminator updates, each on the real samples and as many generated ones, followed
+// by one generator update on a batch of the same size
+func (g *GAN) Step(real [][]float64) (GANStep, error) {
+	if err := g.check(); err != nil {
+		return GANStep{}, err
+	}
+
+	if len(real) == 0 {
+		return GANStep{}, ErrDimensionMismatch
+	}
+
+	for i, x := range real {
+		if err := g.Discriminator.checkInput(x); err != nil {
+			return GANStep{}, fmt.Errorf("real sample %d: %w", i, err)
+		}
+	}
+
+	var (
+		step     GANStep
+		updates  = g.DiscriminatorSteps
+		label    = g.RealLabel
+		gOpt     = g.GeneratorOptimizer
+		dOpt     = g.DiscriminatorOptimizer
+		batch    = float64(len(real))
+		gen, dis = g.Generator, g.Discriminator
+	)
+
+	if updates < 1 {
+		updates = 1
+	}
+
+	if label == 0 {
+		label = 1
+	}
+
+	if gOpt == nil {
+		gOpt = SGD{}
+	}
+
+	if dOpt == nil {
+		dOpt = SGD{}
+	}
+
+	for u := 0; u < updates; u++ {
+		var total Gradients
+
+		step.DiscriminatorLoss = 0
+
+		for _, x := range real {
+			realTarget, fakeTarget := label, 0.0
+
+			if g.FlipLabels > 0 && rand.Float64() < g.FlipLabels {
+				realTarget, fakeTarget = fakeTarget, realTarget
+			}
+
+			fake := gen.Calc(g.noise())
+
+			for _, s := range []struct {
+				input  []float64
+				target float64
+			}{{x, realTarget}, {fake, fakeTarget}} {
+				expected := []float64{s.target}
+				step.DiscriminatorLoss += dis.cost(dis.Calc(s.input), expected)
+
+				if total == nil {
+					total = dis.Gradients(s.input, expected)
+				} else {
+					total = total.Add(dis.Gradients(s.input, expected))
+				}
+			}
+		}
+
+		dOpt.Step(dis, total.Scale(1/(2*batch)), dis.learnRate)
+		step.DiscriminatorLoss /= 2 * batch
+	}
+
+	var total Gradients
+
+	for range real {
+		z := g.noise()
+		fake := gen.Calc(z)
+		step.GeneratorLoss += dis.cost(dis.Calc(fake), []float64{1})
+
+		grads := gen.outputGradients(z, dis.CostGradient(fake, []float64{1}))
+
+		if total == nil {
+			total = grads
+		} else {
+			total = total.Add(grads)
+		}
+	}
+
+	gOpt.Step(gen, total.Scale(1/batch), gen.learnRate)
+	step.GeneratorLoss /= batch
+
+	return step, nil
+}
@@ -0,0 +1,70 @@
+package nn
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+// gradCheckStep is the step used for the finite difference estimates
+const gradCheckStep = 1e-5
+
+// GradCheck compares the gradients found by backpropagation for a single sample against finite difference estimates
+// of them, returning the largest relative error found in each layer. Errors much larger than around 1e-6 usually
+// point to a mistake in a layer's Backward. The weights of dense layers are copied before being nudged, so the
+// network can be in use elsewhere while it is checked. Dropout is turned off for the check.
+func GradCheck(n Network, input, expected []float64) []float64 {
+	n = n.Copy()
+
+	for i := range n.layers {
+		n.layers[i].dropout = 0
+	}
+
+	grads := n.Gradients(input, expected)
+	res := make([]float64, n.h)
+
+	for i := range n.layers {
+		params := n.layers[i].params()
+
+		for j := range params {
+			d := params[j].(*mat.Dense)
+
+			if n.layers[i].custom == nil {
+				d = mat.DenseCopyOf(d)
+				params[j] = d
+				n.layers[i].setParams(params)
+			}
+
+			r, c := d.Dims()
+
+			for y := 0; y < r; y++ {
+				for x := 0; x < c; x++ {
+					v := d.At(y, x)
+
+					d.Set(y, x, v+gradCheckStep)
+					plus := n.cost(n.Calc(input), expected)
+
+					d.Set(y, x, v-gradCheckStep)
+					minus := n.cost(n.Calc(input), expected)
+
+					d.Set(y, x, v)
+
+					numeric := (plus - minus) / (2 * gradCheckStep)
+					analytic := 0.0
+
+					if grads[i] != nil {
+						analytic = grads[i][j].At(y, x)
+					}
+
+					res[i] = math.Max(res[i], relativeError(analytic, numeric))
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+// relativeError calculates the difference between a and b relative to their size
+func relativeError(a, b float64) float64 {
+	return math.Abs(a-b) / math.Max(math.Abs(a)+math.Abs(b), 1e-8)
+}
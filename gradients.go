@@ -0,0 +1,87 @@
+package nn
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+// Gradients holds the gradient of the cost with respect to every parameter of a network. Gradients[i] belongs to
+// layer i and holds the gradients of its weights, its biases, the weights of each of its projection skips and then
+// its PReLU slope, or those of a custom layer's Params in order. Layers without biases have no bias gradient, and
+// layers with tied weights have no weight gradient as theirs is added to that of the layer they share weights with.
+// Layers that don't lead to an output have no gradients.
+type Gradients [][]mat.Matrix
+
+// Add returns the sum of two sets of gradients for the same network
+func (g Gradients) Add(o Gradients) Gradients {
+	res := make(Gradients, len(g))
+
+	for i := range g {
+		if g[i] == nil {
+			res[i] = o[i]
+			continue
+		}
+
+		if o[i] == nil {
+			res[i] = g[i]
+			continue
+		}
+
+		res[i] = make([]mat.Matrix, len(g[i]))
+
+		for j := range g[i] {
+			res[i][j] = add(g[i][j], o[i][j])
+		}
+	}
+
+	return res
+}
+
+// Scale returns the gradients multiplied by f
+func (g Gradients) Scale(f float64) Gradients {
+	res := make(Gradients, len(g))
+
+	for i := range g {
+		if g[i] == nil {
+			continue
+		}
+
+		res[i] = make([]mat.Matrix, len(g[i]))
+
+		for j := range g[i] {
+			res[i][j] = scl(f, g[i][j])
+		}
+	}
+
+	return res
+}
+
+// LayerNorms returns the euclidean norm of the gradients of each layer
+func (g Gradients) LayerNorms() []float64 {
+	res := make([]float64, len(g))
+
+	for i := range g {
+		total := 0.0
+
+		for _, m := range g[i] {
+			total += math.Pow(mat.Norm(m, 2), 2)
+		}
+
+		res[i] = math.Sqrt(total)
+	}
+
+	return res
+}
+
+// Norm returns the euclidean norm of all of the gradients together
+func (g Gradients) Norm() float64 {
+	total := 0.0
+
+	for i := range g {
+		for _, m := range g[i] {
+			total += math.Pow(mat.Norm(m, 2), 2)
+		}
+	}
+
+	return math.Sqrt(total)
+}
@@ -0,0 +1,24 @@
+package nn
+
+import (
+	"math"
+)
+
+// defaultGradientNoiseDecay is the annealing exponent suggested by Neelakantan et al.
+const defaultGradientNoiseDecay = 0.55
+
+// gradientNoise wraps an optimizer to add annealed Gaussian noise to the gradients of every step
+type gradientNoise struct {
+	Optimizer
+
+	eta, gamma float64
+	step       int
+}
+
+// Step implements Optimizer. The noise of step t has a variance of eta/(1+t)^gamma.
+func (g *gradientNoise) Step(n *Network, grads Gradients, rate float64) {
+	std := math.Sqrt(g.eta / math.Pow(float64(1+g.step), g.gamma))
+	g.step++
+
+	g.Optimizer.Step(n, grads.Add(n.noise(std)), rate)
+}
@@ -0,0 +1,164 @@
+package nn
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/mat"
+)
+
+var (
+	errInvalidGraph = errors.New("invalid layer graph")
+)
+
+// Graph describes the layout of a network with any number of inputs, outputs and branches. Every node added to it
+// gets an index which later nodes use to read from it.
+type Graph struct {
+	nodes []graphNode
+}
+
+// graphNode is either an input or a dense layer in a Graph
+type graphNode struct {
+	input   bool
+	size    int
+	sources []int
+	custom  Layer
+	noBias  bool
+}
+
+// NewGraph Creates an empty Graph
+func NewGraph() *Graph {
+	return &Graph{}
+}
+
+// Input adds an input of the given size and returns its index
+func (g *Graph) Input(size int) int {
+	g.nodes = append(g.nodes, graphNode{input: true, size: size})
+	return len(g.nodes) - 1
+}
+
+// Dense adds a layer of the given size reading from every one of sources and returns its index
+func (g *Graph) Dense(size int, sources ...int) int {
+	g.nodes = append(g.nodes, graphNode{size: size, sources: sources})
+	return len(g.nodes) - 1
+}
+
+// DenseNoBias adds a dense layer without biases, for layers followed by a normalisation layer that would cancel them
+// out, and returns its index
+func (g *Graph) DenseNoBias(size int, sources ...int) int {
+	g.nodes = append(g.nodes, graphNode{size: size, sources: sources, noBias: true})
+	return len(g.nodes) - 1
+}
+
+// Network builds a Network from the graph, using the given nodes as its outputs. Any source of a layer past the
+// first is connected using a projection skip.
+func (g *Graph) Network(outputs []int, learn float64, random bool) (Network, error) {
+	var (
+		n     = Network{learnRate: learn}
+		order = make([]int, len(g.nodes)) // order maps node indices to activation indices
+	)
+
+	for i, node := range g.nodes {
+		if node.input {
+			order[i] = len(n.inputs)
+			n.inputs = append(n.inputs, node.size)
+			n.i += node.size
+		}
+	}
+
+	for i, node := range g.nodes {
+		if node.input {
+			continue
+		}
+
+		if node.size < 1 || len(node.sources) == 0 {
+			return Network{}, errInvalidGraph
+		}
+
+		for _, s := range node.sources {
+			if s < 0 || s >= i {
+				return Network{}, errInvalidGraph
+			}
+		}
+
+		order[i] = len(n.inputs) + len(n.layers)
+
+		if node.custom != nil {
+			if len(node.sources) != 1 {
+				return Network{}, errInvalidGraph
+			}
+
+			n.layers = append(n.layers, layer{from: order[node.sources[0]], custom: node.custom})
+			n.h++
+			continue
+		}
+
+		l := newLayer(node.size, n.size(order[node.sources[0]]), random)
+		l.from = order[node.sources[0]]
+
+		if node.noBias {
+			l.biases = nil
+		}
+
+		n.layers = append(n.layers, l)
+		n.h++
+	}
+
+	for i, node := range g.nodes {
+		if len(node.sources) < 2 {
+			continue
+		}
+
+		for _, s := range node.sources[1:] {
+			if err := n.AddSkip(order[s], order[i], true); err != nil {
+				return Network{}, err
+			}
+
+			if random {
+				l := &n.layers[order[i]-len(n.inputs)]
+				r, c := l.skips[len(l.skips)-1].weights.Dims()
+				l.skips[len(l.skips)-1].weights = mat.NewDense(r, c, randomArray(r*c, -1, 1))
+			}
+		}
+	}
+
+	if len(n.inputs) == 0 || len(outputs) == 0 {
+		return Network{}, errInvalidGraph
+	}
+
+	for _, o := range outputs {
+		if o < 0 || o >= len(g.nodes) {
+			return Network{}, errInvalidGraph
+		}
+
+		n.outputs = append(n.outputs, order[o])
+		n.heads = append(n.heads, defaultHead)
+		n.o += g.nodes[o].size
+	}
+
+	return n, nil
+}
+
+// graph recreates the Graph described by saved options, without any of its skips. customs holds the already loaded
+// user defined layers.
+func (opts NetworkOptions) graph(customs []Layer) *Graph {
+	g := NewGraph()
+
+	for _, size := range opts.Inputs {
+		g.Input(size)
+	}
+
+	for i, size := range opts.Sizes {
+		if customs[i] != nil {
+			g.Layer(customs[i], opts.From[i])
+			continue
+		}
+
+		if i < len(opts.NoBias) && opts.NoBias[i] {
+			g.DenseNoBias(size, opts.From[i])
+			continue
+		}
+
+		g.Dense(size, opts.From[i])
+	}
+
+	return g
+}
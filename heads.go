@@ -0,0 +1,132 @@
+package nn
+
+import (
+	"errors"
+)
+
+var (
+	errInvalidHead = errors.New("invalid head")
+)
+
+// Head configures one output of a network. Heads are trained jointly, with the cost of each multiplied by its
+// weight before being combined.
+type Head struct {
+	Size       int
+	Activation Activation
+	Loss       Loss
+	Weight     float64
+}
+
+// head holds the parts of a Head that belong to the network rather than to the output layer
+type head struct {
+	loss   Loss
+	weight float64
+}
+
+// defaultHead is used for outputs that haven't been configured
+var defaultHead = head{loss: SquaredError, weight: 1}
+
+// NewMultiHeadNetwork Creates a new Network with a shared chain of hidden layers followed by one output layer per
+// head. Heads with no activation, loss or weight set use Sigmoid, SquaredError and 1.
+func NewMultiHeadNetwork(inputs int, hidden []int, heads []Head, learn float64, random bool) (Network, error) {
+	g := NewGraph()
+	a := g.Input(inputs)
+
+	for _, size := range hidden {
+		a = g.Dense(size, a)
+	}
+
+	outputs := make([]int, len(heads))
+
+	for i, h := range heads {
+		outputs[i] = g.Dense(h.Size, a)
+	}
+
+	n, err := g.Network(outputs, learn, random)
+	if err != nil {
+		return Network{}, err
+	}
+
+	for i, h := range heads {
+		err = n.SetHead(i, h)
+		if err != nil {
+			return Network{}, err
+		}
+	}
+
+	return n, nil
+}
+
+// SetHead configures the output at the given position in the network's outputs. The head's size must match the
+// output. Heads with no activation, loss or weight set use Sigmoid, SquaredError and 1.
+func (n *Network) SetHead(output int, h Head) error {
+	if output < 0 || output >= len(n.outputs) || n.size(n.outputs[output]) != h.Size {
+		return errInvalidHead
+	}
+
+	if h.Activation == (Activation{}) {
+		h.Activation = Sigmoid
+	}
+
+	if h.Loss == (Loss{}) {
+		h.Loss = SquaredError
+	}
+
+	if h.Weight == 0 {
+		h.Weight = 1
+	}
+
+	if !h.Activation.valid() || !h.Loss.valid() {
+		return errInvalidHead
+	}
+
+	a := n.outputs[output]
+
+	if a >= len(n.inputs) && n.layers[a-len(n.inputs)].custom == nil {
+		n.layers[a-len(n.inputs)].setActivation(h.Activation)
+	}
+
+	n.heads[output] = head{loss: h.Loss, weight: h.Weight}
+
+	return nil
+}
+
+// Heads returns the configuration of each output of the network
+func (n Network) Heads() []Head {
+	res := make([]Head, len(n.outputs))
+
+	for i, a := range n.outputs {
+		res[i] = Head{
+			Size:   n.size(a),
+			Loss:   n.heads[i].loss,
+			Weight: n.heads[i].weight,
+		}
+
+		if a >= len(n.inputs) {
+			res[i].Activation = n.layers[a-len(n.inputs)].activation
+		}
+	}
+
+	return res
+}
+
+// cost calculates the combined loss of every head for a single sample
+func (n Network) cost(got, expected []float64) float64 {
+	if len(got) != n.o {
+		panic(ErrDimensionMismatch)
+	}
+
+	if len(expected) != n.o {
+		panic(DimensionError{Arg: "expected output", Unit: "values", Got: len(expected), Want: n.o})
+	}
+
+	sizes := n.outputSizes()
+	gs, es := split(got, sizes), split(expected, sizes)
+	total := 0.0
+
+	for i, h := range n.heads {
+		total += h.weight * h.loss.cost(gs[i], es[i])
+	}
+
+	return total
+}
@@ -0,0 +1,111 @@
+package nn
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/floats"
+	"math"
+	"math/rand"
+)
+
+var (
+	errInvalidHessian = errors.New("curvature needs samples and a vector with a value for every parameter")
+)
+
+// hessianStep is the length of the step in weight space the Hessian-vector product is estimated over
+const hessianStep = 1e-4
+
+// HessianVector returns the product of the Hessian of the average cost of the samples with a vector, both laid out as
+// by Params. It is estimated from the change in the gradient over a small step each way along the vector, which costs
+// two passes over the samples and is accurate to around 1e-6 for smooth activations. Dropout is turned off for it.
+func (n Network) HessianVector(inputs, expected [][]float64, v []float64) ([]float64, error) {
+	params := n.Params()
+
+	if len(inputs) == 0 || len(v) != len(params) {
+		return nil, errInvalidHessian
+	}
+
+	if err := n.checkSamples(inputs, expected); err != nil {
+		return nil, err
+	}
+
+	eval := n.withoutDropout()
+
+	return eval.hessianVector(params, inputs, expected, v)
+}
+
+// hessianVector estimates the product of the Hessian at params with v by central differences of the gradient
+func (n *Network) hessianVector(params []float64, inputs, expected [][]float64, v []float64) ([]float64, error) {
+	norm := floats.Norm(v, 2)
+
+	if norm == 0 {
+		return make([]float64, len(v)), nil
+	}
+
+	eps := hessianStep / norm
+	shifted := make([]float64, len(params))
+
+	floats.AddScaledTo(shifted, params, eps, v)
+
+	_, plus, err := n.batchGradient(shifted, inputs, expected)
+	if err != nil {
+		return nil, err
+	}
+
+	floats.AddScaledTo(shifted, params, -eps, v)
+
+	_, minus, err := n.batchGradient(shifted, inputs, expected)
+	if err != nil {
+		return nil, err
+	}
+
+	floats.Sub(plus, minus)
+	floats.Scale(1/(2*eps), plus)
+
+	return plus, nil
+}
+
+// Sharpness returns the eigenvalue of the Hessian of the average cost of the samples with the largest magnitude, found
+// by power iteration with HessianVector, which near a minimum is its largest eigenvalue. It measures how sharply the
+// cost curves around the network's parameters: flat minima with a small sharpness tend to generalise better, and SGD
+// becomes unstable once it exceeds 2 divided by the learning rate. Each iteration costs two passes over the samples,
+// and 20 to 50 usually suffice.
+func (n Network) Sharpness(inputs, expected [][]float64, iterations int) (float64, error) {
+	if len(inputs) == 0 || iterations < 1 || n.Params() == nil {
+		return 0, errInvalidHessian
+	}
+
+	if err := n.checkSamples(inputs, expected); err != nil {
+		return 0, err
+	}
+
+	var (
+		eval   = n.withoutDropout()
+		params = n.Params()
+		v      = make([]float64, len(params))
+		lambda = 0.0
+	)
+
+	for i := range v {
+		v[i] = rand.NormFloat64()
+	}
+
+	floats.Scale(1/floats.Norm(v, 2), v)
+
+	for it := 0; it < iterations; it++ {
+		hv, err := eval.hessianVector(params, inputs, expected, v)
+		if err != nil {
+			return 0, err
+		}
+
+		lambda = floats.Dot(v, hv)
+		norm := floats.Norm(hv, 2)
+
+		if norm == 0 || math.IsNaN(norm) {
+			break
+		}
+
+		floats.ScaleTo(v, 1/norm, hv)
+	}
+
+	return lambda, nil
+}
@@ -0,0 +1,114 @@
+package nn
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/mat"
+	"sync"
+)
+
+var (
+	errHogwildOptions = errors.New(
+		"hogwild training only supports SGD without a NaN policy, EMA, weight decay, gradient noise, adversarial " +
+			"samples, input corruption or EWC")
+)
+
+// hogwildStats accumulates the statistics of the samples handled by one worker
+type hogwildStats struct {
+	loss, gradNorm float64
+	layerGradNorms []float64
+}
+
+// ownParams gives every dense layer its own *mat.Dense parameters, so they can be updated in place
+func (n *Network) ownParams() {
+	for i := range n.layers {
+		if n.layers[i].custom != nil {
+			continue
+		}
+
+		params := n.layers[i].params()
+
+		for j := range params {
+			params[j] = mat.DenseCopyOf(params[j])
+		}
+
+		n.layers[i].setParams(params)
+	}
+}
+
+// applyInPlace moves every parameter against its gradient as ApplyGradients does, but by writing straight into the
+// parameter matrices
+func (n *Network) applyInPlace(grads Gradients, rate float64) {
+	for i := range n.layers {
+		if grads[i] == nil {
+			continue
+		}
+
+		steps := n.layers[i].steps()
+
+		for j, p := range n.layers[i].params() {
+			raw := p.(*mat.Dense).RawMatrix()
+			g := grads[i][j]
+
+			for y := 0; y < raw.Rows; y++ {
+				for x := 0; x < raw.Cols; x++ {
+					raw.Data[y*raw.Stride+x] -= rate * steps[j] * g.At(y, x)
+				}
+			}
+		}
+	}
+}
+
+// hogwildEpoch trains on the samples in order, split between workers which update the shared weights at the same time
+// without any locking. Updates from different workers can overwrite each other, but when each sample only changes a
+// small part of the weights this rarely matters and the speedup is close to the number of workers. The network's
+// parameters must have been given to it by ownParams first. The loss of each sample is recorded in losses if it isn't
+// nil.
+//
+// The workers deliberately race on the weights, so the race detector will report this mode.
+func (n *Network) hogwildEpoch(inputs, expected [][]float64, order []int, losses []float64, workers int, e *Epoch) {
+	var (
+		wg    sync.WaitGroup
+		stats = make([]hogwildStats, workers)
+	)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func(w int) {
+			defer wg.Done()
+
+			s := &stats[w]
+			s.layerGradNorms = make([]float64, n.h)
+
+			for k := w; k < len(order); k += workers {
+				i := order[k]
+				grads := n.Gradients(inputs[i], expected[i])
+				n.applyInPlace(grads, n.learnRate)
+
+				s.gradNorm += grads.Norm()
+
+				for l, norm := range grads.LayerNorms() {
+					s.layerGradNorms[l] += norm
+				}
+
+				loss := n.cost(n.Calc(inputs[i]), expected[i])
+				s.loss += loss
+
+				if losses != nil {
+					losses[i] = loss
+				}
+			}
+		}(w)
+	}
+
+	wg.Wait()
+
+	for _, s := range stats {
+		e.Loss += s.loss
+		e.GradNorm += s.gradNorm
+
+		for l, norm := range s.layerGradNorms {
+			e.LayerGradNorms[l] += norm / float64(len(order))
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package nn
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// Hook receives the index of a layer along with its weighted input and activation every time the layer is
+// evaluated, whether by Calc or during training. z is nil for user defined layers. Hooks must not modify either
+// matrix. Hooks are called from whichever goroutine evaluates the network, so they may run concurrently.
+type Hook func(layer int, z, activation mat.Matrix)
+
+// AddHook registers a hook to be called for every layer of the network
+func (n *Network) AddHook(h Hook) {
+	n.hooks = append(n.hooks, h)
+}
+
+// ClearHooks removes every registered hook
+func (n *Network) ClearHooks() {
+	n.hooks = nil
+}
+
+// runHooks passes a layer's weighted input and activation to every hook
+func (n Network) runHooks(layer int, z, activation mat.Matrix) {
+	for _, h := range n.hooks {
+		h(layer, z, activation)
+	}
+}
@@ -0,0 +1,261 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+var (
+	errInvalidSearch = errors.New("a search needs at least one trial and parameters with Min below Max")
+	errNoFiniteTrial = errors.New("no trial had a finite loss")
+)
+
+// Param is a hyperparameter to search over, such as a learning rate or a layer size
+type Param struct {
+	Name     string
+	Min, Max float64
+
+	// Log searches the range on a logarithmic scale, which suits learning rates and other values spanning several
+	// orders of magnitude. Min must be positive.
+	Log bool
+
+	// Integer rounds the values tried to whole numbers, for sizes and counts
+	Integer bool
+}
+
+// value maps a position between 0 and 1 to a value of the parameter
+func (p Param) value(u float64) float64 {
+	var v float64
+
+	if p.Log {
+		v = math.Exp(lerp(u, 0, 1, math.Log(p.Min), math.Log(p.Max)))
+	} else {
+		v = lerp(u, 0, 1, p.Min, p.Max)
+	}
+
+	if p.Integer {
+		v = math.Round(v)
+	}
+
+	return v
+}
+
+// position maps a value of the parameter back to a position between 0 and 1
+func (p Param) position(v float64) float64 {
+	if p.Log {
+		return lerp(math.Log(v), math.Log(p.Min), math.Log(p.Max), 0, 1)
+	}
+
+	return lerp(v, p.Min, p.Max, 0, 1)
+}
+
+// Trial is one evaluation of the objective during a search
+type Trial struct {
+	Params map[string]float64 `json:"params"`
+	Loss   float64            `json:"loss"`
+}
+
+// Objective trains a network with the given hyperparameters, keyed by their names, and returns it along with its
+// loss, such as the validation cost. Lower losses are better.
+type Objective func(params map[string]float64) (Network, float64, error)
+
+// TPE searches hyperparameters with a tree-structured Parzen estimator. After some random trials, it splits the trials
+// so far into the best few and the rest, models each group's density over every parameter, and tries whichever of
+// a number of candidates drawn from the good density is most likely to be good rather than bad. It finds good values
+// in far fewer trials than a grid or random search.
+type TPE struct {
+	Params []Param
+
+	// Trials is the number of times the objective is evaluated
+	Trials int
+
+	// Startup is the number of random trials before the search starts modelling, 10 or a third of Trials when zero,
+	// whichever is smaller
+	Startup int
+
+	// Gamma is the fraction of trials counted as good, 0.25 when zero
+	Gamma float64
+
+	// Candidates is the number of values drawn from the good density for every trial, 24 when zero
+	Candidates int
+}
+
+// Optimize evaluates the objective Trials times and returns the network of the trial with the lowest loss along with
+// every trial in the order they ran. Trials with a NaN or infinite loss are kept in the history but never chosen as the
+// best, and if every trial had one an error is returned along with the history.
+func (t TPE) Optimize(objective Objective) (Network, []Trial, error) {
+	if t.Trials < 1 || len(t.Params) == 0 {
+		return Network{}, nil, errInvalidSearch
+	}
+
+	for _, p := range t.Params {
+		if p.Min >= p.Max || p.Log && p.Min <= 0 {
+			return Network{}, nil, fmt.Errorf("%w: %s", errInvalidSearch, p.Name)
+		}
+	}
+
+	startup := t.Startup
+
+	if startup == 0 {
+		startup = t.Trials / 3
+
+		if startup > 10 {
+			startup = 10
+		}
+	}
+
+	var (
+		best     Network
+		bestLoss = math.Inf(1)
+		history  = make([]Trial, 0, t.Trials)
+		points   [][]float64 // points[i] holds the position of every parameter in trial i
+	)
+
+	for i := 0; i < t.Trials; i++ {
+		var u []float64
+
+		if i < startup || i < 2 {
+			u = make([]float64, len(t.Params))
+
+			for j := range u {
+				u[j] = rand.Float64()
+			}
+		} else {
+			u = t.suggest(history, points)
+		}
+
+		params := make(map[string]float64, len(t.Params))
+
+		for j, p := range t.Params {
+			params[p.Name] = p.value(u[j])
+
+			// Rounded values are modelled where they actually are
+			u[j] = p.position(params[p.Name])
+		}
+
+		n, loss, err := objective(params)
+		if err != nil {
+			return best, history, fmt.Errorf("trial %d: %w", i+1, err)
+		}
+
+		history = append(history, Trial{Params: params, Loss: loss})
+		points = append(points, u)
+
+		if loss < bestLoss && !math.IsInf(loss, -1) {
+			best, bestLoss = n, loss
+		}
+	}
+
+	if math.IsInf(bestLoss, 1) {
+		return Network{}, history, errNoFiniteTrial
+	}
+
+	return best, history, nil
+}
+
+// suggest chooses the positions of the parameters for the next trial
+func (t TPE) suggest(history []Trial, points [][]float64) []float64 {
+	gamma, candidates := t.Gamma, t.Candidates
+
+	if gamma == 0 {
+		gamma = 0.25
+	}
+
+	if candidates == 0 {
+		candidates = 24
+	}
+
+	var order []int
+
+	for i, trial := range history {
+		if !math.IsNaN(trial.Loss) {
+			order = append(order, i)
+		}
+	}
+
+	sort.Slice(order, func(a, b int) bool {
+		return history[order[a]].Loss < history[order[b]].Loss
+	})
+
+	split := int(math.Ceil(gamma * float64(len(order))))
+
+	if split < 1 {
+		split = 1
+	}
+
+	var good, bad [][]float64
+
+	for k, i := range order {
+		if k < split {
+			good = append(good, points[i])
+		} else {
+			bad = append(bad, points[i])
+		}
+	}
+
+	res := make([]float64, len(t.Params))
+
+	// Every parameter is modelled on its own, as the estimator is a product of independent densities
+	for j := range t.Params {
+		l, g := newParzen(good, j), newParzen(bad, j)
+		bestScore := math.Inf(-1)
+
+		for c := 0; c < candidates; c++ {
+			x := l.sample()
+
+			if score := math.Log(l.density(x)) - math.Log(g.density(x)); score > bestScore {
+				res[j], bestScore = x, score
+			}
+		}
+	}
+
+	return res
+}
+
+// parzen is a density over positions between 0 and 1 made of a Gaussian around every observation and a uniform
+// prior, which keeps every position possible
+type parzen struct {
+	centers   []float64
+	bandwidth float64
+}
+
+// newParzen models the density of parameter j over a set of points
+func newParzen(points [][]float64, j int) parzen {
+	p := parzen{centers: make([]float64, len(points))}
+
+	for i, point := range points {
+		p.centers[i] = point[j]
+	}
+
+	// Fewer observations are spread more widely
+	p.bandwidth = math.Max(0.05, math.Pow(float64(len(points)+1), -0.2)/2)
+
+	return p
+}
+
+// density returns the density of the model at x
+func (p parzen) density(x float64) float64 {
+	// The prior counts as one observation
+	d := 1.0
+
+	for _, c := range p.centers {
+		z := (x - c) / p.bandwidth
+		d += math.Exp(-z*z/2) / (p.bandwidth * math.Sqrt(2*math.Pi))
+	}
+
+	return d / float64(len(p.centers)+1)
+}
+
+// sample draws a position from the model
+func (p parzen) sample() float64 {
+	k := rand.Intn(len(p.centers) + 1)
+
+	if k == len(p.centers) {
+		return rand.Float64()
+	}
+
+	return math.Min(1, math.Max(0, p.centers[k]+rand.NormFloat64()*p.bandwidth))
+}
@@ -0,0 +1,128 @@
+package nn
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"math"
+)
+
+var (
+	errInvalidImageOptions = errors.New("invalid image options")
+)
+
+// ImageOptions configures ImageInput
+type ImageOptions struct {
+	// Width and Height are the size the image is resized to, using bilinear interpolation. The image's own size is
+	// kept when they are zero.
+	Width, Height int
+
+	// RGB gives every pixel three values, red, green and blue, instead of a single grey level
+	RGB bool
+
+	// Mean and Std standardise each channel after it has been scaled to the range 0 to 1, as (v-Mean[c])/Std[c].
+	// They need one value per channel, and are ignored when empty.
+	Mean, Std []float64
+}
+
+// channels returns the number of values per pixel
+func (opts ImageOptions) channels() int {
+	if opts.RGB {
+		return 3
+	}
+
+	return 1
+}
+
+// ImageInput converts an image into a network input. Pixels are stored row by row from the top left, with the
+// channels of each pixel next to each other, and every value is scaled to the range 0 to 1 before Mean and Std are
+// applied. Transparent pixels are treated as black.
+func ImageInput(img image.Image, opts ImageOptions) ([]float64, error) {
+	var (
+		b        = img.Bounds()
+		sw, sh   = b.Dx(), b.Dy()
+		channels = opts.channels()
+		w, h     = opts.Width, opts.Height
+	)
+
+	if w == 0 && h == 0 {
+		w, h = sw, sh
+	}
+
+	if w <= 0 || h <= 0 || sw == 0 || sh == 0 || len(opts.Mean) != len(opts.Std) ||
+		(len(opts.Mean) != 0 && len(opts.Mean) != channels) {
+		return nil, errInvalidImageOptions
+	}
+
+	src := make([]float64, sw*sh*channels)
+
+	for y := 0; y < sh; y++ {
+		for x := 0; x < sw; x++ {
+			p := src[(y*sw+x)*channels:]
+			c := img.At(b.Min.X+x, b.Min.Y+y)
+
+			if !opts.RGB {
+				p[0] = float64(color.Gray16Model.Convert(c).(color.Gray16).Y) / 0xffff
+				continue
+			}
+
+			r, g, bl, _ := c.RGBA()
+			p[0], p[1], p[2] = float64(r)/0xffff, float64(g)/0xffff, float64(bl)/0xffff
+		}
+	}
+
+	res := src
+
+	if w != sw || h != sh {
+		res = resize(src, sw, sh, w, h, channels)
+	}
+
+	if len(opts.Mean) != 0 {
+		for i := range res {
+			c := i % channels
+			res[i] = (res[i] - opts.Mean[c]) / opts.Std[c]
+		}
+	}
+
+	return res, nil
+}
+
+// resize scales an image stored as values row by row using bilinear interpolation
+func resize(src []float64, sw, sh, w, h, channels int) []float64 {
+	res := make([]float64, w*h*channels)
+
+	// sample finds the source pixels either side of a target pixel and how far it is between them
+	sample := func(i, size, target int) (int, int, float64) {
+		s := (float64(i)+0.5)*float64(size)/float64(target) - 0.5
+		s = math.Max(0, math.Min(s, float64(size-1)))
+
+		lo := int(s)
+		hi := lo + 1
+
+		if hi >= size {
+			hi = size - 1
+		}
+
+		return lo, hi, s - float64(lo)
+	}
+
+	for y := 0; y < h; y++ {
+		y0, y1, fy := sample(y, sh, h)
+
+		for x := 0; x < w; x++ {
+			x0, x1, fx := sample(x, sw, w)
+
+			for c := 0; c < channels; c++ {
+				at := func(x, y int) float64 {
+					return src[(y*sw+x)*channels+c]
+				}
+
+				top := lerp(fx, 0, 1, at(x0, y0), at(x1, y0))
+				bottom := lerp(fx, 0, 1, at(x0, y1), at(x1, y1))
+				res[(y*w+x)*channels+c] = lerp(fy, 0, 1, top, bottom)
+			}
+		}
+	}
+
+	return res
+}
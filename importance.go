@@ -0,0 +1,85 @@
+package nn
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+var (
+	errInvalidRepeats = errors.New("repeats must be at least 1")
+)
+
+// FeatureImportance is how much the cost of a network grows when one of its input features is shuffled
+type FeatureImportance struct {
+	Feature int
+
+	// Importance is the average increase in cost over the repeats, and Std is its standard deviation
+	Importance float64
+	Std        float64
+}
+
+// PermutationImportance measures how much each input feature matters to a trained network. Every feature is shuffled
+// across the evaluation set in turn, breaking its link to the expected outputs, and the increase in cost over the
+// unshuffled set is averaged over repeats shuffles. The results are ranked from most to least important. Features with
+// an importance near or below zero aren't being used by the network.
+func (n Network) PermutationImportance(inputs, expected [][]float64, repeats int) ([]FeatureImportance, error) {
+	if len(inputs) == 0 {
+		return nil, ErrDimensionMismatch
+	}
+
+	if repeats < 1 {
+		return nil, errInvalidRepeats
+	}
+
+	if err := n.checkSamples(inputs, expected); err != nil {
+		return nil, err
+	}
+
+	baseline := n.Cost(inputs, expected)
+
+	// Shuffled rows share everything but the permuted feature with the originals
+	shuffled := make([][]float64, len(inputs))
+	for i := range inputs {
+		shuffled[i] = append([]float64(nil), inputs[i]...)
+	}
+
+	res := make([]FeatureImportance, n.i)
+	order := make([]int, len(inputs))
+
+	for f := range res {
+		var mean, m2 float64
+
+		for r := 0; r < repeats; r++ {
+			for i := range order {
+				order[i] = i
+			}
+
+			rand.Shuffle(len(order), func(i, j int) {
+				order[i], order[j] = order[j], order[i]
+			})
+
+			for i, j := range order {
+				shuffled[i][f] = inputs[j][f]
+			}
+
+			delta := n.Cost(shuffled, expected) - baseline
+			d := delta - mean
+			mean += d / float64(r+1)
+			m2 += d * (delta - mean)
+		}
+
+		for i := range shuffled {
+			shuffled[i][f] = inputs[i][f]
+		}
+
+		res[f] = FeatureImportance{Feature: f, Importance: mean, Std: math.Sqrt(m2 / float64(repeats))}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Importance > res[j].Importance
+	})
+
+	return res, nil
+}
@@ -0,0 +1,205 @@
+package nn
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/floats"
+	"gonum.org/v1/gonum/optimize"
+	"math"
+	"runtime"
+)
+
+var (
+	errInvalidLBFGS = errors.New("L-BFGS needs samples and positive iterations")
+)
+
+// LBFGS trains a network on the whole training set at once with the limited memory BFGS method, using gonum's
+// optimize package. Every iteration it builds an estimate of the cost's curvature from its last few steps, and takes a
+// step along the resulting direction chosen by a line search that checks both the decrease in cost and the change in
+// slope. On small problems that fit in a single batch it converges in far fewer passes over the data than SGD, with no
+// learning rate to tune. Dropout is turned off while it trains.
+type LBFGS struct {
+	// Memory is the number of past steps the curvature is estimated from, 10 when zero
+	Memory int
+
+	// Iterations is the maximum number of steps taken
+	Iterations int
+
+	// Tolerance stops training once no component of the gradient is larger than it, 1e-6 when zero
+	Tolerance float64
+}
+
+// Train minimises the average cost of the samples and returns the cost after every iteration. Training stops early
+// when the gradient vanishes, the cost stops improving or the line search can't find a step that lowers the cost.
+func (l LBFGS) Train(n *Network, inputs, expected [][]float64) ([]float64, error) {
+	if len(inputs) == 0 || l.Iterations < 1 {
+		return nil, errInvalidLBFGS
+	}
+
+	if err := n.checkSamples(inputs, expected); err != nil {
+		return nil, err
+	}
+
+	memory, tolerance := l.Memory, l.Tolerance
+
+	if memory == 0 {
+		memory = 10
+	}
+
+	if tolerance == 0 {
+		tolerance = 1e-6
+	}
+
+	var (
+		eval     = n.withoutDropout()
+		cache    = batchCache{n: &eval, inputs: inputs, expected: expected}
+		recorder = &lossRecorder{}
+	)
+
+	problem := optimize.Problem{
+		Func: func(x []float64) float64 {
+			f, _ := cache.at(x)
+			return f
+		},
+		Grad: func(grad, x []float64) {
+			_, g := cache.at(x)
+			copy(grad, g)
+		},
+		Status: func() (optimize.Status, error) {
+			if cache.err != nil {
+				return optimize.Failure, cache.err
+			}
+
+			return optimize.NotTerminated, nil
+		},
+	}
+
+	settings := &optimize.Settings{
+		MajorIterations:   l.Iterations,
+		GradientThreshold: tolerance,
+		Recorder:          recorder,
+	}
+
+	result, err := optimize.Minimize(problem, n.Params(), settings, &optimize.LBFGS{Store: memory})
+
+	// A line search that fails only means no better step could be found, which ends training like convergence does
+	if err != nil && (result == nil || cache.err != nil || !isLinesearchFailure(err)) {
+		return recorder.losses, err
+	}
+
+	if err := n.SetParams(result.X); err != nil {
+		return recorder.losses, err
+	}
+
+	return recorder.losses, nil
+}
+
+// isLinesearchFailure checks for the errors optimize returns when a line search can't lower the cost any further
+func isLinesearchFailure(err error) bool {
+	return errors.Is(err, optimize.ErrLinesearcherFailure) || errors.Is(err, optimize.ErrNoProgress) ||
+		errors.Is(err, optimize.ErrLinesearcherBound)
+}
+
+// batchCache remembers the cost and gradient of the last parameters it was asked about, as optimize evaluates the
+// cost and gradient separately but batchGradient finds both in the same passes over the samples
+type batchCache struct {
+	n                *Network
+	inputs, expected [][]float64
+
+	x   []float64
+	f   float64
+	g   []float64
+	err error
+}
+
+// at returns the average cost and its gradient for a set of parameters
+func (c *batchCache) at(x []float64) (float64, []float64) {
+	if c.x != nil && floats.Equal(c.x, x) {
+		return c.f, c.g
+	}
+
+	f, g, err := c.n.batchGradient(x, c.inputs, c.expected)
+	if err != nil {
+		c.err = err
+		return math.NaN(), make([]float64, len(x))
+	}
+
+	c.x, c.f, c.g = append(c.x[:0], x...), f, g
+
+	return f, g
+}
+
+// lossRecorder collects the cost after every iteration of an optimize method
+type lossRecorder struct {
+	losses []float64
+}
+
+// Init implements optimize.Recorder
+func (r *lossRecorder) Init() error {
+	return nil
+}
+
+// Record implements optimize.Recorder
+func (r *lossRecorder) Record(loc *optimize.Location, op optimize.Operation, _ *optimize.Stats) error {
+	if op == optimize.MajorIteration {
+		r.losses = append(r.losses, loc.F)
+	}
+
+	return nil
+}
+
+// withoutDropout returns a copy of the network with dropout turned off, so that its cost is deterministic
+func (n *Network) withoutDropout() Network {
+	res := n.Copy()
+
+	for i := range res.layers {
+		res.layers[i].dropout = 0
+	}
+
+	return res
+}
+
+// batchGradient sets the network's parameters and returns the average cost of the samples along with its gradient,
+// laid out as by Params
+func (n *Network) batchGradient(params []float64, inputs, expected [][]float64) (float64, []float64, error) {
+	if err := n.SetParams(params); err != nil {
+		return 0, nil, err
+	}
+
+	var (
+		workers = runtime.GOMAXPROCS(0)
+		costs   = make([]float64, workers)
+		grads   = make([][]float64, workers)
+		jobs    = make([]func() error, workers)
+		net     = *n
+	)
+
+	for w := range jobs {
+		w := w
+
+		jobs[w] = func() error {
+			grads[w] = make([]float64, len(params))
+
+			for i := w; i < len(inputs); i += workers {
+				costs[w] += net.cost(net.Calc(inputs[i]), expected[i])
+				floats.Add(grads[w], net.flatGradients(net.Gradients(inputs[i], expected[i])))
+			}
+
+			return nil
+		}
+	}
+
+	if err := parallel(jobs); err != nil {
+		return 0, nil, err
+	}
+
+	cost, grad := 0.0, make([]float64, len(params))
+
+	for w := range jobs {
+		cost += costs[w]
+		floats.Add(grad, grads[w])
+	}
+
+	floats.Scale(1/float64(len(inputs)), grad)
+
+	return cost / float64(len(inputs)), grad, nil
+}
@@ -0,0 +1,133 @@
+package nn
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+var (
+	errUnknownLoss = errors.New("unknown loss")
+)
+
+// crossEntropyClamp keeps outputs away from 0 and 1 so cross entropy stays finite
+const crossEntropyClamp = 1e-12
+
+// Loss measures how far an output of the network is from its expected value
+type Loss struct {
+	Name string
+}
+
+var (
+	// SquaredError is the sum of the squared differences, and is used by every output unless told otherwise
+	SquaredError = Loss{Name: "squared"}
+
+	// CrossEntropy is the binary cross entropy, suited to sigmoid outputs trained on values of 0 or 1
+	CrossEntropy = Loss{Name: "crossentropy"}
+
+	// CategoricalCrossEntropy is the cross entropy between probability distributions, suited to softmax outputs trained
+	// on one hot classes
+	CategoricalCrossEntropy = Loss{Name: "categorical"}
+
+	// KLDivergence is the Kullback-Leibler divergence of the outputs from the expected distribution, suited to softmax
+	// outputs trained on soft targets such as a teacher network's outputs. It differs from CategoricalCrossEntropy only
+	// by the entropy of the targets, so it has the same gradients but is zero for a perfect match.
+	KLDivergence = Loss{Name: "kl"}
+
+	// Hinge is the hinge loss max(0, 1 - y·output) of a margin classifier, where y is -1 for expected values of 0 and
+	// 1 for expected values of 1. It suits linear outputs, which should be positive for the positive class.
+	Hinge = Loss{Name: "hinge"}
+)
+
+// valid reports whether the loss is one the package knows about
+func (l Loss) valid() bool {
+	switch l {
+	case SquaredError, CrossEntropy, CategoricalCrossEntropy, KLDivergence, Hinge:
+		return true
+	}
+
+	return false
+}
+
+// cost calculates the loss of an output, skipping expected values that are NaN
+func (l Loss) cost(got, expected mat.Matrix) float64 {
+	r, _ := got.Dims()
+	total := 0.0
+
+	for i := 0; i < r; i++ {
+		g, e := got.At(i, 0), expected.At(i, 0)
+
+		if math.IsNaN(e) {
+			continue
+		}
+
+		switch l {
+		case CrossEntropy:
+			g = math.Min(math.Max(g, crossEntropyClamp), 1-crossEntropyClamp)
+			total -= e*math.Log(g) + (1-e)*math.Log(1-g)
+		case CategoricalCrossEntropy:
+			total -= e * math.Log(math.Max(g, crossEntropyClamp))
+		case KLDivergence:
+			if e > 0 {
+				total += e * (math.Log(e) - math.Log(math.Max(g, crossEntropyClamp)))
+			}
+		case Hinge:
+			total += math.Max(0, 1-(2*e-1)*g)
+		default:
+			total += math.Pow(g-e, 2)
+		}
+	}
+
+	return total
+}
+
+// grad calculates the gradient of the loss with respect to the output
+func (l Loss) grad(got, expected mat.Matrix) mat.Matrix {
+	r, _ := got.Dims()
+	res := mat.NewDense(r, 1, nil)
+
+	for i := 0; i < r; i++ {
+		g, e := got.At(i, 0), expected.At(i, 0)
+
+		if math.IsNaN(e) {
+			continue
+		}
+
+		switch l {
+		case CrossEntropy:
+			g = math.Min(math.Max(g, crossEntropyClamp), 1-crossEntropyClamp)
+			res.Set(i, 0, (g-e)/(g*(1-g)))
+		case CategoricalCrossEntropy, KLDivergence:
+			res.Set(i, 0, -e/math.Max(g, crossEntropyClamp))
+		case Hinge:
+			if y := 2*e - 1; y*g < 1 {
+				res.Set(i, 0, -y)
+			}
+		default:
+			res.Set(i, 0, 2*(g-e))
+		}
+	}
+
+	return res
+}
+
+// Mask returns a copy of expected with every value whose mask is zero replaced by NaN. Expected values that are NaN
+// are treated as padding by every loss, and add nothing to the cost or the gradients, which allows training on
+// targets of different lengths padded to the same size.
+func Mask(expected, mask []float64) []float64 {
+	if len(expected) != len(mask) {
+		panic(ErrDimensionMismatch)
+	}
+
+	res := make([]float64, len(expected))
+
+	for i, e := range expected {
+		res[i] = e
+
+		if mask[i] == 0 {
+			res[i] = math.NaN()
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,316 @@
+package nn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"gonum.org/v1/gonum/mat"
+)
+
+var (
+	errInvalidLSTM = errors.New("invalid LSTM layer")
+)
+
+// lstmName is the name LSTM layers are registered under, so that networks containing them can be loaded and cloned
+const lstmName = "lstm"
+
+func init() {
+	RegisterLayer(lstmName, func() Layer {
+		return &LSTM{}
+	})
+}
+
+// Indices of the LSTM's gates
+const (
+	forgetGate = iota
+	inputGate
+	outputGate
+	cellGate
+)
+
+// LSTM is a layer of long short-term memory cells, which reads a sequence one element at a time and carries what it
+// has seen in a memory its gates learn to keep, update and forget. A network's inputs have a fixed size, so the
+// sequence is packed into the layer's input with PackSequence as steps elements of features values each, and shorter
+// sequences can be padded at the front with zeros. The layer outputs the hidden state of its cells after the last
+// element, or after every element when it returns sequences, which lets LSTM layers be stacked. It is added to a
+// network with Graph.Layer like any other Layer, and NewLSTMNetwork builds the usual LSTM followed by a dense output
+// layer.
+type LSTM struct {
+	features, steps, cells int
+	sequences              bool
+
+	// weights[g] and biases[g] belong to gate g, with the weights reading the element followed by the hidden state
+	weights, biases [4]*mat.Dense
+}
+
+// lstmStep holds everything from a single time step that is needed for backpropagation through time
+type lstmStep struct {
+	xh    mat.Matrix
+	gates [4]mat.Matrix
+	c     mat.Matrix
+}
+
+// NewLSTM Creates an LSTM layer with the given number of memory cells, reading sequences of steps elements of features
+// values each. With sequences set it outputs the hidden state after every element instead of only the last.
+func NewLSTM(features, steps, cells int, sequences, random bool) *LSTM {
+	l := &LSTM{features: features, steps: steps, cells: cells, sequences: sequences}
+
+	for g := range l.weights {
+		if random {
+			l.weights[g] = mat.NewDense(cells, features+cells, randomArray(cells*(features+cells), -1, 1))
+			l.biases[g] = mat.NewDense(cells, 1, randomArray(cells, -1, 1))
+		} else {
+			l.weights[g] = mat.NewDense(cells, features+cells, nil)
+			l.biases[g] = mat.NewDense(cells, 1, nil)
+		}
+	}
+
+	return l
+}
+
+// NewLSTMNetwork Creates a network made of an LSTM layer followed by a dense output layer, reading sequences packed
+// with PackSequence
+func NewLSTMNetwork(features, steps, cells, outputs int, learn float64, random bool) (Network, error) {
+	g := NewGraph()
+	a := g.Input(features * steps)
+	a = g.Layer(NewLSTM(features, steps, cells, false, random), a)
+	a = g.Dense(outputs, a)
+
+	return g.Network([]int{a}, learn, random)
+}
+
+// PackSequence lays a sequence out as a single input, one element after another
+func PackSequence(sequence [][]float64) []float64 {
+	var res []float64
+
+	for _, element := range sequence {
+		res = append(res, element...)
+	}
+
+	return res
+}
+
+// Name implements Layer
+func (l *LSTM) Name() string {
+	return lstmName
+}
+
+// Size implements Layer
+func (l *LSTM) Size() int {
+	if l.sequences {
+		return l.steps * l.cells
+	}
+
+	return l.cells
+}
+
+// Params implements Layer, returning the weights of every gate followed by their biases
+func (l *LSTM) Params() []*mat.Dense {
+	return append(append([]*mat.Dense{}, l.weights[:]...), l.biases[:]...)
+}
+
+// step advances the cell state and hidden state by one element of the sequence
+func (l *LSTM) step(x, h, c mat.Matrix) lstmStep {
+	xh := mat.NewDense(l.features+l.cells, 1, nil)
+
+	for j := 0; j < l.features; j++ {
+		xh.Set(j, 0, x.At(j, 0))
+	}
+
+	for j := 0; j < l.cells; j++ {
+		xh.Set(l.features+j, 0, h.At(j, 0))
+	}
+
+	s := lstmStep{xh: xh}
+
+	for g := range l.weights {
+		z := add(dot(l.weights[g], xh), l.biases[g])
+
+		if g == cellGate {
+			s.gates[g] = fun(tanh, z)
+			continue
+		}
+
+		s.gates[g] = fun(sigmoid, z)
+	}
+
+	s.c = add(mul(s.gates[forgetGate], c), mul(s.gates[inputGate], s.gates[cellGate]))
+
+	return s
+}
+
+// hidden calculates the hidden state produced by a step
+func (s lstmStep) hidden() mat.Matrix {
+	return mul(s.gates[outputGate], fun(tanh, s.c))
+}
+
+// run passes a packed sequence through the cells, returning every step
+func (l *LSTM) run(input mat.Matrix) []lstmStep {
+	if r, _ := input.Dims(); r != l.features*l.steps {
+		panic(DimensionError{Arg: "LSTM input", Unit: "values", Got: r, Want: l.features * l.steps})
+	}
+
+	var (
+		h     mat.Matrix = mat.NewDense(l.cells, 1, nil)
+		c     mat.Matrix = mat.NewDense(l.cells, 1, nil)
+		in               = mat.DenseCopyOf(input)
+		steps            = make([]lstmStep, l.steps)
+	)
+
+	for t := range steps {
+		x := in.Slice(t*l.features, (t+1)*l.features, 0, 1)
+		steps[t] = l.step(x, h, c)
+		h, c = steps[t].hidden(), steps[t].c
+	}
+
+	return steps
+}
+
+// Forward implements Layer
+func (l *LSTM) Forward(input mat.Matrix) mat.Matrix {
+	steps := l.run(input)
+
+	if !l.sequences {
+		return steps[len(steps)-1].hidden()
+	}
+
+	res := mat.NewDense(l.steps*l.cells, 1, nil)
+
+	for t, s := range steps {
+		h := s.hidden()
+
+		for j := 0; j < l.cells; j++ {
+			res.Set(t*l.cells+j, 0, h.At(j, 0))
+		}
+	}
+
+	return res
+}
+
+// Backward implements Layer using backpropagation through time
+func (l *LSTM) Backward(input, grad mat.Matrix) (mat.Matrix, []mat.Matrix) {
+	steps := l.run(input)
+
+	var (
+		dWeights  [4]mat.Matrix
+		dBiases   [4]mat.Matrix
+		dh        mat.Matrix = mat.NewDense(l.cells, 1, nil)
+		dc        mat.Matrix = mat.NewDense(l.cells, 1, nil)
+		inputGrad            = mat.NewDense(l.features*l.steps, 1, nil)
+		outGrad              = mat.DenseCopyOf(grad)
+	)
+
+	for g := range l.weights {
+		dWeights[g] = mat.NewDense(l.cells, l.features+l.cells, nil)
+		dBiases[g] = mat.NewDense(l.cells, 1, nil)
+	}
+
+	for t := len(steps) - 1; t >= 0; t-- {
+		s := steps[t]
+
+		switch {
+		case l.sequences:
+			dh = add(dh, outGrad.Slice(t*l.cells, (t+1)*l.cells, 0, 1))
+		case t == len(steps)-1:
+			dh = add(dh, grad)
+		}
+
+		var prevC mat.Matrix = mat.NewDense(l.cells, 1, nil)
+		if t > 0 {
+			prevC = steps[t-1].c
+		}
+
+		tc := fun(tanh, s.c)
+		dc = add(dc, mul(mul(dh, s.gates[outputGate]), fun(dTanhOutput, tc)))
+
+		var deltas [4]mat.Matrix
+
+		deltas[forgetGate] = mul(mul(dc, prevC), fun(dSigmoidOutput, s.gates[forgetGate]))
+		deltas[inputGate] = mul(mul(dc, s.gates[cellGate]), fun(dSigmoidOutput, s.gates[inputGate]))
+		deltas[outputGate] = mul(mul(dh, tc), fun(dSigmoidOutput, s.gates[outputGate]))
+		deltas[cellGate] = mul(mul(dc, s.gates[inputGate]), fun(dTanhOutput, s.gates[cellGate]))
+
+		var dxh mat.Matrix = mat.NewDense(l.features+l.cells, 1, nil)
+
+		for g := range l.weights {
+			dWeights[g] = add(dWeights[g], dot(deltas[g], s.xh.T()))
+			dBiases[g] = add(dBiases[g], deltas[g])
+			dxh = add(dxh, dot(l.weights[g].T(), deltas[g]))
+		}
+
+		// The part of the gradient reaching the element goes to the input, and the rest carries on to the previous step
+		for j := 0; j < l.features; j++ {
+			inputGrad.Set(t*l.features+j, 0, dxh.At(j, 0))
+		}
+
+		dh = mat.DenseCopyOf(dxh).Slice(l.features, l.features+l.cells, 0, 1)
+		dc = mul(dc, s.gates[forgetGate])
+	}
+
+	return inputGrad, append(dWeights[:], dBiases[:]...)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler
+func (l *LSTM) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+
+	sequences := 0
+
+	if l.sequences {
+		sequences = 1
+	}
+
+	for _, v := range []int{l.features, l.steps, l.cells, sequences} {
+		_ = binary.Write(&buf, binary.LittleEndian, uint64(v))
+	}
+
+	params, err := marshalMatrices([][]*mat.Dense{l.weights[:], l.biases[:]})
+	if err != nil {
+		return nil, err
+	}
+
+	buf.Write(params)
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, restoring a layer written by MarshalBinary
+func (l *LSTM) UnmarshalBinary(data []byte) error {
+	var header [4]uint64
+
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &header); err != nil {
+		return errInvalidLSTM
+	}
+
+	params, err := unmarshalMatrices(data[binary.Size(header):])
+	if err != nil || len(params) != 2 || len(params[0]) != 4 || len(params[1]) != 4 {
+		return errInvalidLSTM
+	}
+
+	features, steps, cells := int(header[0]), int(header[1]), int(header[2])
+
+	if features < 1 || steps < 1 || cells < 1 || header[3] > 1 {
+		return errInvalidLSTM
+	}
+
+	for g := 0; g < 4; g++ {
+		w, b := params[0][g], params[1][g]
+
+		if w == nil || b == nil {
+			return errInvalidLSTM
+		}
+
+		wr, wc := w.Dims()
+		br, bc := b.Dims()
+
+		if wr != cells || wc != features+cells || br != cells || bc != 1 {
+			return errInvalidLSTM
+		}
+
+		l.weights[g], l.biases[g] = w, b
+	}
+
+	l.features, l.steps, l.cells, l.sequences = features, steps, cells, header[3] == 1
+
+	return nil
+}
@@ -1,4 +1,3 @@
 package nn
 
-// This may allow you to run this as a command later on.
-// As of now will not do anything :P
+// The command line tool for training and running networks lives in cmd/nn.
@@ -0,0 +1,121 @@
+package nn
+
+import (
+	"math"
+	"sort"
+)
+
+// Metric accumulates a measure of a network's performance over a set of samples. TrainWith evaluates the metrics in
+// TrainOptions.Metrics after every epoch, making a fresh one for every set it is evaluated on.
+type Metric interface {
+	// Update adds the network's output for a sample and the expected output
+	Update(pred, target []float64)
+
+	// Result returns the metric over every sample added so far
+	Result() float64
+}
+
+// MeanAbsoluteError is the average absolute difference between outputs and their expected values
+type MeanAbsoluteError struct {
+	sum   float64
+	count int
+}
+
+// Update implements Metric
+func (m *MeanAbsoluteError) Update(pred, target []float64) {
+	for i := range pred {
+		m.sum += math.Abs(pred[i] - target[i])
+		m.count++
+	}
+}
+
+// Result implements Metric
+func (m *MeanAbsoluteError) Result() float64 {
+	if m.count == 0 {
+		return 0
+	}
+
+	return m.sum / float64(m.count)
+}
+
+// TopKAccuracy is the fraction of samples whose expected class, the largest expected value, is among the K largest
+// outputs. A K of 0 or 1 is the usual accuracy of a classifier.
+type TopKAccuracy struct {
+	K int
+
+	hits, count int
+}
+
+// Update implements Metric
+func (m *TopKAccuracy) Update(pred, target []float64) {
+	class := 0
+
+	for i, v := range target {
+		if v > target[class] {
+			class = i
+		}
+	}
+
+	order := make([]int, len(pred))
+
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		return pred[order[a]] > pred[order[b]]
+	})
+
+	k := m.K
+
+	if k < 1 {
+		k = 1
+	}
+
+	for i := 0; i < k && i < len(order); i++ {
+		if order[i] == class {
+			m.hits++
+			break
+		}
+	}
+
+	m.count++
+}
+
+// Result implements Metric
+func (m *TopKAccuracy) Result() float64 {
+	if m.count == 0 {
+		return 0
+	}
+
+	return float64(m.hits) / float64(m.count)
+}
+
+// evaluateMetrics evaluates every metric over a set of samples, returning nil if there are no metrics or samples
+func (n Network) evaluateMetrics(metrics map[string]func() Metric, inputs, expected [][]float64) map[string]float64 {
+	if len(metrics) == 0 || len(inputs) == 0 {
+		return nil
+	}
+
+	ms := make(map[string]Metric, len(metrics))
+
+	for name, newMetric := range metrics {
+		ms[name] = newMetric()
+	}
+
+	for i, input := range inputs {
+		pred := n.Calc(input)
+
+		for _, m := range ms {
+			m.Update(pred, expected[i])
+		}
+	}
+
+	res := make(map[string]float64, len(ms))
+
+	for name, m := range ms {
+		res[name] = m.Result()
+	}
+
+	return res
+}
@@ -0,0 +1,107 @@
+package nn
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+)
+
+// epochDurationBuckets are the bucket bounds, in seconds, of the epoch duration histogram
+var epochDurationBuckets = []float64{0.001, 0.01, 0.1, 0.5, 1, 5, 10, 30, 60, 300}
+
+// TrainingMetrics records the progress of training and serves it to Prometheus. Pass its Callback to TrainWith and
+// register it as an http.Handler, usually on /metrics, or register it as a prometheus.Collector with a registry of
+// your own.
+type TrainingMetrics struct {
+	epochs     prometheus.Counter
+	samples    prometheus.Counter
+	throughput prometheus.Gauge
+	loss       prometheus.Gauge
+	valLoss    prometheus.Gauge
+	learnRate  prometheus.Gauge
+	gradNorm   prometheus.Gauge
+	durations  prometheus.Histogram
+
+	handler http.Handler
+}
+
+// NewTrainingMetrics Creates an empty TrainingMetrics
+func NewTrainingMetrics() *TrainingMetrics {
+	gauge := func(name, help string) prometheus.Gauge {
+		return prometheus.NewGauge(prometheus.GaugeOpts{Name: name, Help: help})
+	}
+
+	m := &TrainingMetrics{
+		epochs: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "nn_training_epochs_total",
+			Help: "Epochs completed.",
+		}),
+		samples: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "nn_training_samples_total",
+			Help: "Training samples processed.",
+		}),
+		throughput: gauge("nn_training_samples_per_second", "Training throughput during the last epoch."),
+		loss:       gauge("nn_training_loss", "Average training cost of the last epoch."),
+		valLoss:    gauge("nn_training_validation_loss", "Average validation cost of the last epoch with validation data."),
+		learnRate:  gauge("nn_training_learn_rate", "Learning rate of the last epoch."),
+		gradNorm:   gauge("nn_training_grad_norm", "Average gradient norm of the last epoch."),
+		durations: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "nn_training_epoch_duration_seconds",
+			Help:    "Time taken by each epoch.",
+			Buckets: epochDurationBuckets,
+		}),
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(m)
+	m.handler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+
+	return m
+}
+
+// collectors returns every metric recorded
+func (m *TrainingMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{
+		m.epochs, m.samples, m.throughput, m.loss, m.valLoss, m.learnRate, m.gradNorm, m.durations,
+	}
+}
+
+// Callback records a finished epoch
+func (m *TrainingMetrics) Callback(_ *Network, e Epoch) error {
+	m.epochs.Inc()
+	m.samples.Add(float64(e.Samples))
+
+	if e.Duration > 0 {
+		m.throughput.Set(float64(e.Samples) / e.Duration.Seconds())
+	}
+
+	m.loss.Set(e.Loss)
+
+	if e.HasValidation {
+		m.valLoss.Set(e.ValLoss)
+	}
+	m.learnRate.Set(e.LearnRate)
+	m.gradNorm.Set(e.GradNorm)
+	m.durations.Observe(e.Duration.Seconds())
+
+	return nil
+}
+
+// Describe implements prometheus.Collector
+func (m *TrainingMetrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector
+func (m *TrainingMetrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format
+func (m *TrainingMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.handler.ServeHTTP(w, r)
+}
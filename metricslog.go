@@ -0,0 +1,170 @@
+package nn
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	errUnknownLogFormat = errors.New("unknown metrics log format")
+)
+
+// metricsLogColumns are the columns written for every epoch, in order
+var metricsLogColumns = []string{"epoch", "loss", "val_loss", "learn_rate", "grad_norm", "samples", "duration_seconds"}
+
+// valLossColumn is the index of val_loss in metricsLogColumns
+const valLossColumn = 2
+
+// MetricsLog appends the details of every epoch to a CSV or JSON lines file, so training runs can be analysed
+// without parsing what TrainWith prints. Each epoch is written as soon as it finishes. Epochs without validation data
+// leave val_loss empty, or out of the JSON object. Epochs with activation statistics also get act_mean_i and act_std_i
+// columns for each layer i. JSON has no NaN or infinity, so those are written as the strings "NaN", "+Inf" and "-Inf".
+type MetricsLog struct {
+	f    *os.File
+	csv  *csv.Writer
+	json *json.Encoder
+
+	// columns is set when the CSV header is written, which happens with the first epoch of a new file
+	columns    []string
+	needHeader bool
+}
+
+// NewMetricsLog Opens filename for appending, creating it if it doesn't exist. Files ending in .csv are written as CSV
+// with a header row when the file is new, and files ending in .jsonl or .json are written with one JSON object per
+// line.
+func NewMetricsLog(filename string) (*MetricsLog, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+
+	if ext != ".csv" && ext != ".jsonl" && ext != ".json" {
+		return nil, errUnknownLogFormat
+	}
+
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &MetricsLog{f: f}
+
+	if ext != ".csv" {
+		m.json = json.NewEncoder(f)
+		return m, nil
+	}
+
+	m.csv = csv.NewWriter(f)
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	m.needHeader = info.Size() == 0
+
+	return m, nil
+}
+
+// Callback writes a row for the finished epoch
+func (m *MetricsLog) Callback(_ *Network, e Epoch) error {
+	columns := metricsLogColumns
+	values := []float64{
+		float64(e.Epoch),
+		e.Loss,
+		e.ValLoss,
+		e.LearnRate,
+		e.GradNorm,
+		float64(e.Samples),
+		e.Duration.Seconds(),
+	}
+
+	if len(e.ActivationMeans) > 0 {
+		columns = append(columns[:len(columns):len(columns)], activationColumns(len(e.ActivationMeans))...)
+		values = append(values, e.ActivationMeans...)
+		values = append(values, e.ActivationStds...)
+	}
+
+	if m.json != nil {
+		row := make(map[string]interface{}, len(values))
+
+		for i, v := range values {
+			row[columns[i]] = jsonValue(v)
+		}
+
+		if !e.HasValidation {
+			delete(row, columns[valLossColumn])
+		}
+
+		return m.json.Encode(row)
+	}
+
+	if m.needHeader {
+		if err := m.write(columns); err != nil {
+			return err
+		}
+
+		m.columns, m.needHeader = columns, false
+	}
+
+	record := make([]string, len(values))
+
+	for i, v := range values {
+		record[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+
+	if !e.HasValidation {
+		record[valLossColumn] = ""
+	}
+
+	// Epochs without activation statistics leave their columns empty
+	for len(record) < len(m.columns) {
+		record = append(record, "")
+	}
+
+	return m.write(record)
+}
+
+// jsonValue returns a value JSON can encode, writing NaN and infinities as the strings CSV uses for them
+func jsonValue(v float64) interface{} {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+
+	return v
+}
+
+// activationColumns returns the names of the activation statistics columns for a number of layers
+func activationColumns(layers int) []string {
+	columns := make([]string, 0, 2*layers)
+
+	for i := 0; i < layers; i++ {
+		columns = append(columns, "act_mean_"+strconv.Itoa(i))
+	}
+
+	for i := 0; i < layers; i++ {
+		columns = append(columns, "act_std_"+strconv.Itoa(i))
+	}
+
+	return columns
+}
+
+// write writes a CSV record and flushes it to the file
+func (m *MetricsLog) write(record []string) error {
+	err := m.csv.Write(record)
+	if err != nil {
+		return err
+	}
+
+	m.csv.Flush()
+	return m.csv.Error()
+}
+
+// Close closes the file
+func (m *MetricsLog) Close() error {
+	return m.f.Close()
+}
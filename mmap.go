@@ -0,0 +1,40 @@
+package nn
+
+import (
+	"unsafe"
+)
+
+// littleEndian is true when the host stores numbers the same way safetensors files do
+var littleEndian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+// MapSafetensors replaces the weights of the network with those in a safetensors file like LoadSafetensors, but
+// memory-maps the file instead of reading it. 64 bit tensors are used straight from the mapping where the host allows
+// it, so pages are only read from disk when they are first used, which cuts the memory and start up time of large
+// models. The mapping is copy on write, so training the network never changes the file, and stays mapped for as long
+// as the process runs. Platforms without mmap read the file instead.
+func (n *Network) MapSafetensors(filename string) error {
+	data, release, err := mapFile(filename)
+	if err != nil {
+		return err
+	}
+
+	if err = n.loadSafetensors(data, true); err != nil {
+		_ = release()
+		return err
+	}
+
+	return nil
+}
+
+// float64s reinterprets little endian 64 bit floats as a slice without copying them, if they are aligned and the host
+// is little endian
+func float64s(raw []byte) ([]float64, bool) {
+	if !littleEndian || len(raw) == 0 || uintptr(unsafe.Pointer(&raw[0]))%unsafe.Alignof(float64(0)) != 0 {
+		return nil, false
+	}
+
+	return unsafe.Slice((*float64)(unsafe.Pointer(&raw[0])), len(raw)/8), true
+}
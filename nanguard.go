@@ -0,0 +1,107 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+var (
+	errNonFinite = errors.New("non-finite value")
+)
+
+// NaNPolicy decides what TrainWith does when training produces a NaN or infinite value
+type NaNPolicy int
+
+const (
+	// NaNIgnore doesn't check for non-finite values at all
+	NaNIgnore NaNPolicy = iota
+
+	// NaNAbort stops training with an error describing where the value appeared
+	NaNAbort
+
+	// NaNSkip leaves out the update of any sample whose gradients aren't finite. Training still stops with an
+	// error if an update with finite gradients makes the weights overflow.
+	NaNSkip
+
+	// NaNRollback skips samples like NaNSkip, and if the weights still become non-finite restores them to where they
+	// were at the end of the last epoch. Optimizer state is not rolled back.
+	NaNRollback
+)
+
+// finite checks that every value of a matrix is finite
+func finite(m mat.Matrix) bool {
+	r, c := m.Dims()
+
+	for y := 0; y < r; y++ {
+		for x := 0; x < c; x++ {
+			v := m.At(y, x)
+
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// nonFinite returns the first layer with a non-finite gradient, or -1 if they are all finite
+func (g Gradients) nonFinite() int {
+	for i := range g {
+		for _, m := range g[i] {
+			if !finite(m) {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// nonFinite returns the first layer with a non-finite parameter, or -1 if they are all finite
+func (n Network) nonFinite() int {
+	for i, l := range n.layers {
+		for _, p := range l.params() {
+			if !finite(p) {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// guardedStep evaluates a sample and applies its gradients as allowed by policy, returning whether the update was
+// applied. checkpoint holds the parameters to roll back to for NaNRollback.
+func (n *Network) guardedStep(opt Optimizer, input, expected []float64, policy NaNPolicy,
+	checkpoint [][]*mat.Dense) (Gradients, bool, error) {
+	grads := n.Gradients(input, expected)
+
+	if policy == NaNIgnore {
+		opt.Step(n, grads, n.learnRate)
+		return grads, true, nil
+	}
+
+	if l := grads.nonFinite(); l >= 0 {
+		if policy == NaNAbort {
+			return nil, false, fmt.Errorf("%w in the gradients of layer %d", errNonFinite, l)
+		}
+
+		return nil, false, nil
+	}
+
+	opt.Step(n, grads, n.learnRate)
+
+	if l := n.nonFinite(); l >= 0 {
+		if policy != NaNRollback {
+			return nil, false, fmt.Errorf("%w in the weights of layer %d after an update", errNonFinite, l)
+		}
+
+		n.loadParams(checkpoint)
+		return nil, false, nil
+	}
+
+	return grads, true, nil
+}
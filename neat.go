@@ -0,0 +1,799 @@
+package nn
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+var (
+	errInvalidNEAT = errors.New("NEAT needs inputs, outputs, a population of at least 2 and positive generations")
+)
+
+// The coefficients of the compatibility distance between genomes, weighing excess genes, disjoint genes and the
+// average weight difference of matching genes
+const (
+	neatExcess   = 1.0
+	neatDisjoint = 1.0
+	neatWeight   = 0.4
+)
+
+// NodeKind is the role of a node in a Genome
+type NodeKind int
+
+const (
+	// InputNode takes one of the genome's inputs
+	InputNode NodeKind = iota
+
+	// BiasNode always outputs 1, so a connection from it acts as a bias
+	BiasNode
+
+	// HiddenNode is a node added by mutation
+	HiddenNode
+
+	// OutputNode gives one of the genome's outputs
+	OutputNode
+)
+
+// NodeGene is a node of a Genome. Nodes made by splitting the same connection in different genomes share an ID.
+type NodeGene struct {
+	ID   int      `json:"id"`
+	Kind NodeKind `json:"kind"`
+}
+
+// ConnectionGene is a weighted connection between two nodes of a Genome. The same connection in different genomes
+// has the same innovation number, which lines genomes up for crossover and speciation.
+type ConnectionGene struct {
+	In         int     `json:"in"`
+	Out        int     `json:"out"`
+	Weight     float64 `json:"weight"`
+	Enabled    bool    `json:"enabled"`
+	Innovation int     `json:"innovation"`
+}
+
+// Genome is a network evolved by NEAT, made of individual nodes and the connections between them rather than layers.
+// Connections never form a cycle, so it is evaluated in a single pass like a Network.
+type Genome struct {
+	Nodes       []NodeGene       `json:"nodes"`
+	Connections []ConnectionGene `json:"connections"`
+
+	// Hidden and Output are the activations of the hidden and output nodes. A Softmax output is applied across every
+	// output node.
+	Hidden Activation `json:"hidden"`
+	Output Activation `json:"output"`
+
+	// Fitness is the fitness the genome was given in the last generation it was evaluated in
+	Fitness float64 `json:"fitness"`
+}
+
+// Calc evaluates the genome for an input
+func (g Genome) Calc(data []float64) []float64 {
+	var (
+		values  = make(map[int]float64, len(g.Nodes))
+		pending = make(map[int]int, len(g.Nodes)) // pending counts the inputs of a node that haven't been evaluated
+		ready   []int
+		outputs []int
+		in      = 0
+	)
+
+	for _, c := range g.Connections {
+		if c.Enabled {
+			pending[c.Out]++
+		}
+	}
+
+	for _, node := range g.Nodes {
+		switch node.Kind {
+		case InputNode:
+			if in < len(data) {
+				values[node.ID] = data[in]
+			}
+
+			in++
+		case BiasNode:
+			values[node.ID] = 1
+		case OutputNode:
+			outputs = append(outputs, node.ID)
+		}
+
+		if pending[node.ID] == 0 {
+			ready = append(ready, node.ID)
+		}
+	}
+
+	sums := make(map[int]float64, len(g.Nodes))
+	kinds := g.kinds()
+
+	for len(ready) > 0 {
+		id := ready[0]
+		ready = ready[1:]
+
+		switch kinds[id] {
+		case HiddenNode:
+			values[id] = g.Hidden.activateOne(sums[id])
+		case OutputNode:
+			values[id] = g.Output.activateOne(sums[id])
+		}
+
+		for _, c := range g.outgoing(id) {
+			sums[c.Out] += c.Weight * values[id]
+			pending[c.Out]--
+
+			if pending[c.Out] == 0 {
+				ready = append(ready, c.Out)
+			}
+		}
+	}
+
+	res := make([]float64, len(outputs))
+
+	for i, id := range outputs {
+		res[i] = values[id]
+	}
+
+	if g.Output == Softmax {
+		for i, id := range outputs {
+			res[i] = sums[id]
+		}
+
+		return softmax(res)
+	}
+
+	return res
+}
+
+// activateOne applies the activation to a single value
+func (a Activation) activateOne(v float64) float64 {
+	return a.apply(mat.NewDense(1, 1, []float64{v})).At(0, 0)
+}
+
+// kinds maps the ID of every node to its kind
+func (g Genome) kinds() map[int]NodeKind {
+	res := make(map[int]NodeKind, len(g.Nodes))
+
+	for _, node := range g.Nodes {
+		res[node.ID] = node.Kind
+	}
+
+	return res
+}
+
+// outgoing returns the enabled connections leaving a node
+func (g Genome) outgoing(id int) []ConnectionGene {
+	var res []ConnectionGene
+
+	for _, c := range g.Connections {
+		if c.Enabled && c.In == id {
+			res = append(res, c)
+		}
+	}
+
+	return res
+}
+
+// Size returns the number of hidden nodes and enabled connections in the genome
+func (g Genome) Size() (hidden, connections int) {
+	for _, node := range g.Nodes {
+		if node.Kind == HiddenNode {
+			hidden++
+		}
+	}
+
+	for _, c := range g.Connections {
+		if c.Enabled {
+			connections++
+		}
+	}
+
+	return hidden, connections
+}
+
+// clone returns a copy of the genome that shares nothing with it
+func (g Genome) clone() Genome {
+	res := g
+	res.Nodes = append([]NodeGene(nil), g.Nodes...)
+	res.Connections = append([]ConnectionGene(nil), g.Connections...)
+
+	return res
+}
+
+// reaches reports whether there is a path of connections, enabled or not, from one node to another
+func (g Genome) reaches(from, to int) bool {
+	seen := map[int]bool{from: true}
+	stack := []int{from}
+
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if id == to {
+			return true
+		}
+
+		for _, c := range g.Connections {
+			if c.In == id && !seen[c.Out] {
+				seen[c.Out] = true
+				stack = append(stack, c.Out)
+			}
+		}
+	}
+
+	return false
+}
+
+// distance is the compatibility distance between two genomes, which grows with the genes they don't share and the
+// difference between the weights of the ones they do
+func (g Genome) distance(other Genome) float64 {
+	var (
+		genes    = make(map[int]ConnectionGene, len(g.Connections))
+		maxG     = -1
+		maxO     = -1
+		matching = 0
+		diff     = 0.0
+		excess   = 0
+		disjoint = 0
+	)
+
+	for _, c := range g.Connections {
+		genes[c.Innovation] = c
+
+		if c.Innovation > maxG {
+			maxG = c.Innovation
+		}
+	}
+
+	for _, c := range other.Connections {
+		if c.Innovation > maxO {
+			maxO = c.Innovation
+		}
+	}
+
+	shared := make(map[int]bool, len(other.Connections))
+
+	for _, c := range other.Connections {
+		if gc, ok := genes[c.Innovation]; ok {
+			matching++
+			diff += math.Abs(gc.Weight - c.Weight)
+			shared[c.Innovation] = true
+		} else if c.Innovation > maxG {
+			excess++
+		} else {
+			disjoint++
+		}
+	}
+
+	for _, c := range g.Connections {
+		if shared[c.Innovation] {
+			continue
+		}
+
+		if c.Innovation > maxO {
+			excess++
+		} else {
+			disjoint++
+		}
+	}
+
+	// Small genomes aren't normalised, so that a couple of extra genes still count
+	size := float64(len(g.Connections))
+
+	if len(other.Connections) > len(g.Connections) {
+		size = float64(len(other.Connections))
+	}
+
+	if size < 20 {
+		size = 1
+	}
+
+	res := (neatExcess*float64(excess) + neatDisjoint*float64(disjoint)) / size
+
+	if matching > 0 {
+		res += neatWeight * diff / float64(matching)
+	}
+
+	return res
+}
+
+// NEATGeneration summarises a generation of NEAT
+type NEATGeneration struct {
+	Best    float64 `json:"best"`
+	Mean    float64 `json:"mean"`
+	Species int     `json:"species"`
+
+	// Hidden and Connections are the size of the best genome of the generation
+	Hidden      int `json:"hidden"`
+	Connections int `json:"connections"`
+}
+
+// NEAT evolves the topology of networks along with their weights (NeuroEvolution of Augmenting Topologies). Every
+// genome starts with its inputs connected straight to its outputs, and mutations add nodes and connections over the
+// generations, so the architecture only grows as large as the problem needs. Genomes are grouped into species of
+// similar topologies which compete mostly among themselves, giving new structure time to have its weights tuned
+// before it has to beat established genomes.
+type NEAT struct {
+	Inputs, Outputs int
+
+	// Population is the number of genomes in every generation
+	Population int
+
+	// Hidden and Output are the activations of the genomes' hidden and output nodes, Sigmoid when unset
+	Hidden, Output Activation
+
+	// Threshold is the compatibility distance below which genomes belong to the same species, 3 when zero
+	Threshold float64
+
+	// AddNode and AddConnection are the probabilities of a child gaining a node or a connection, 0.03 and 0.05 when
+	// zero, and WeightMutation the probability of its weights being perturbed, 0.8 when zero
+	AddNode, AddConnection, WeightMutation float64
+
+	// Survival is the fraction of every species allowed to reproduce, 0.2 when zero
+	Survival float64
+
+	// Stagnation is the number of generations a species can go without improving before it is removed, 15 when zero.
+	// The species holding the best genome is never removed.
+	Stagnation int
+
+	// Target, when it isn't zero, stops the evolution as soon as a genome reaches that fitness
+	Target float64
+}
+
+// species is a group of similar genomes
+type species struct {
+	representative Genome
+	members        []int
+	best           float64
+	stale          int
+}
+
+// innovations hands out the innovation numbers of connections and the IDs of nodes, giving the same structural
+// mutation the same numbers wherever it happens
+type innovations struct {
+	connections map[[2]int]int
+	splits      map[int]int
+	nextNode    int
+}
+
+// connection returns the innovation number of a connection between two nodes
+func (inv *innovations) connection(in, out int) int {
+	key := [2]int{in, out}
+
+	if n, ok := inv.connections[key]; ok {
+		return n
+	}
+
+	inv.connections[key] = len(inv.connections)
+
+	return inv.connections[key]
+}
+
+// split returns the ID of the node made by splitting a connection, or a new one if the genome already has that node
+func (inv *innovations) split(g Genome, innovation int) int {
+	id, ok := inv.splits[innovation]
+
+	if ok {
+		if _, exists := g.kinds()[id]; !exists {
+			return id
+		}
+	}
+
+	id = inv.nextNode
+	inv.nextNode++
+
+	if !ok {
+		inv.splits[innovation] = id
+	}
+
+	return id
+}
+
+// Evolve evolves a population for a number of generations and returns the fittest genome found along with a summary
+// of every generation. Higher fitnesses are better and must not be negative. The fitness function is called for every
+// genome of a generation at the same time, so it must be safe for concurrent use.
+func (ne NEAT) Evolve(fitness func(g Genome) float64, generations int) (Genome, []NEATGeneration, error) {
+	if ne.Inputs < 1 || ne.Outputs < 1 || ne.Population < 2 || generations < 1 {
+		return Genome{}, nil, errInvalidNEAT
+	}
+
+	if ne.Hidden == (Activation{}) {
+		ne.Hidden = Sigmoid
+	}
+
+	if ne.Output == (Activation{}) {
+		ne.Output = Sigmoid
+	}
+
+	if !ne.Hidden.valid() || !ne.Output.valid() || ne.Hidden == PReLU || ne.Output == PReLU || ne.Hidden == Softmax {
+		return Genome{}, nil, errUnknownActivation
+	}
+
+	ne.defaults()
+
+	inv := &innovations{
+		connections: make(map[[2]int]int),
+		splits:      make(map[int]int),
+		nextNode:    ne.Inputs + 1 + ne.Outputs,
+	}
+
+	pop := make([]Genome, ne.Population)
+
+	for i := range pop {
+		pop[i] = ne.initial(inv)
+	}
+
+	var (
+		best    Genome
+		history []NEATGeneration
+		groups  []*species
+	)
+
+	best.Fitness = math.Inf(-1)
+
+	for gen := 0; gen < generations; gen++ {
+		jobs := make([]func() error, len(pop))
+
+		for i := range pop {
+			g := &pop[i]
+
+			jobs[i] = func() error {
+				g.Fitness = fitness(*g)
+				return nil
+			}
+		}
+
+		_ = parallel(jobs)
+
+		summary := NEATGeneration{Best: math.Inf(-1)}
+		champion := 0
+
+		for i, g := range pop {
+			summary.Mean += g.Fitness / float64(len(pop))
+
+			if g.Fitness > summary.Best {
+				summary.Best, champion = g.Fitness, i
+			}
+		}
+
+		if pop[champion].Fitness > best.Fitness {
+			best = pop[champion].clone()
+		}
+
+		groups = ne.speciate(pop, groups)
+		summary.Species = len(groups)
+		summary.Hidden, summary.Connections = pop[champion].Size()
+		history = append(history, summary)
+
+		if ne.Target != 0 && best.Fitness >= ne.Target || gen == generations-1 {
+			break
+		}
+
+		pop, groups = ne.reproduce(pop, groups, champion, inv)
+	}
+
+	return best, history, nil
+}
+
+// defaults fills in the options left at zero
+func (ne *NEAT) defaults() {
+	if ne.Threshold == 0 {
+		ne.Threshold = 3
+	}
+
+	if ne.AddNode == 0 {
+		ne.AddNode = 0.03
+	}
+
+	if ne.AddConnection == 0 {
+		ne.AddConnection = 0.05
+	}
+
+	if ne.WeightMutation == 0 {
+		ne.WeightMutation = 0.8
+	}
+
+	if ne.Survival == 0 {
+		ne.Survival = 0.2
+	}
+
+	if ne.Stagnation == 0 {
+		ne.Stagnation = 15
+	}
+}
+
+// initial creates a genome with every input and the bias connected to every output by a random weight
+func (ne NEAT) initial(inv *innovations) Genome {
+	g := Genome{Hidden: ne.Hidden, Output: ne.Output}
+
+	for i := 0; i < ne.Inputs; i++ {
+		g.Nodes = append(g.Nodes, NodeGene{ID: i, Kind: InputNode})
+	}
+
+	g.Nodes = append(g.Nodes, NodeGene{ID: ne.Inputs, Kind: BiasNode})
+
+	for o := 0; o < ne.Outputs; o++ {
+		out := ne.Inputs + 1 + o
+		g.Nodes = append(g.Nodes, NodeGene{ID: out, Kind: OutputNode})
+
+		for in := 0; in <= ne.Inputs; in++ {
+			g.Connections = append(g.Connections, ConnectionGene{
+				In:         in,
+				Out:        out,
+				Weight:     rand.Float64()*2 - 1,
+				Enabled:    true,
+				Innovation: inv.connection(in, out),
+			})
+		}
+	}
+
+	return g
+}
+
+// speciate assigns every genome to the first species whose representative from the last generation it is close
+// enough to, creating species for those which fit none, and drops species left empty
+func (ne NEAT) speciate(pop []Genome, groups []*species) []*species {
+	for _, s := range groups {
+		s.members = nil
+	}
+
+	for i, g := range pop {
+		placed := false
+
+		for _, s := range groups {
+			if g.distance(s.representative) < ne.Threshold {
+				s.members = append(s.members, i)
+				placed = true
+
+				break
+			}
+		}
+
+		if !placed {
+			groups = append(groups, &species{representative: g, members: []int{i}, best: math.Inf(-1)})
+		}
+	}
+
+	res := groups[:0]
+
+	for _, s := range groups {
+		if len(s.members) == 0 {
+			continue
+		}
+
+		// The next generation is compared against a random member of this one
+		s.representative = pop[s.members[rand.Intn(len(s.members))]].clone()
+		res = append(res, s)
+	}
+
+	return res
+}
+
+// reproduce removes stagnant species and breeds the next generation, giving every species a share of it in
+// proportion to its members' fitness divided by its size, so that no single species can take over the population
+func (ne NEAT) reproduce(pop []Genome, groups []*species, champion int, inv *innovations) ([]Genome, []*species) {
+	var (
+		alive  []*species
+		shares []float64
+		total  = 0.0
+	)
+
+	for _, s := range groups {
+		best, holdsChampion := math.Inf(-1), false
+
+		for _, i := range s.members {
+			best = math.Max(best, pop[i].Fitness)
+			holdsChampion = holdsChampion || i == champion
+		}
+
+		if best > s.best {
+			s.best, s.stale = best, 0
+		} else {
+			s.stale++
+		}
+
+		if s.stale >= ne.Stagnation && !holdsChampion {
+			continue
+		}
+
+		share := 0.0
+
+		for _, i := range s.members {
+			if f := pop[i].Fitness; f > 0 {
+				share += f / float64(len(s.members))
+			}
+		}
+
+		alive = append(alive, s)
+		shares = append(shares, share)
+		total += share
+	}
+
+	counts := make([]int, len(alive))
+	remaining := ne.Population
+
+	for i := range alive {
+		if total > 0 {
+			counts[i] = int(float64(ne.Population) * shares[i] / total)
+		} else {
+			counts[i] = ne.Population / len(alive)
+		}
+
+		remaining -= counts[i]
+	}
+
+	// Whatever rounding left over goes to the species with the largest shares
+	order := make([]int, len(alive))
+
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		return shares[order[a]] > shares[order[b]]
+	})
+
+	for i := 0; remaining > 0; i++ {
+		counts[order[i%len(order)]]++
+		remaining--
+	}
+
+	next := make([]Genome, 0, ne.Population)
+
+	for k, s := range alive {
+		if counts[k] == 0 {
+			continue
+		}
+
+		members := append([]int(nil), s.members...)
+
+		sort.SliceStable(members, func(a, b int) bool {
+			return pop[members[a]].Fitness > pop[members[b]].Fitness
+		})
+
+		// The champion of every species of a reasonable size carries on unchanged
+		children := counts[k]
+
+		if len(members) >= 5 {
+			next = append(next, pop[members[0]].clone())
+			children--
+		}
+
+		parents := int(math.Ceil(ne.Survival * float64(len(members))))
+
+		if parents < 1 {
+			parents = 1
+		}
+
+		for c := 0; c < children; c++ {
+			a, b := pop[members[rand.Intn(parents)]], pop[members[rand.Intn(parents)]]
+
+			var child Genome
+
+			if rand.Float64() < 0.25 {
+				child = a.clone()
+			} else {
+				child = crossover(a, b)
+			}
+
+			ne.mutate(&child, inv)
+			next = append(next, child)
+		}
+	}
+
+	return next, alive
+}
+
+// crossover breeds two genomes. Shared connections are inherited from either parent at random, and the rest from the
+// fitter one, whose nodes the child takes.
+func crossover(a, b Genome) Genome {
+	if b.Fitness > a.Fitness {
+		a, b = b, a
+	}
+
+	genes := make(map[int]ConnectionGene, len(b.Connections))
+
+	for _, c := range b.Connections {
+		genes[c.Innovation] = c
+	}
+
+	child := a.clone()
+
+	for i, c := range child.Connections {
+		other, ok := genes[c.Innovation]
+
+		if !ok {
+			continue
+		}
+
+		if rand.Intn(2) == 0 {
+			child.Connections[i].Weight = other.Weight
+		}
+
+		// A connection disabled in either parent usually stays disabled
+		child.Connections[i].Enabled = c.Enabled && other.Enabled || rand.Float64() < 0.25
+	}
+
+	return child
+}
+
+// mutate perturbs a genome's weights and might add a node or a connection to it
+func (ne NEAT) mutate(g *Genome, inv *innovations) {
+	if rand.Float64() < ne.WeightMutation {
+		for i := range g.Connections {
+			if rand.Float64() < 0.9 {
+				g.Connections[i].Weight += rand.NormFloat64() * 0.5
+			} else {
+				g.Connections[i].Weight = rand.Float64()*4 - 2
+			}
+		}
+	}
+
+	if rand.Float64() < ne.AddConnection {
+		ne.addConnection(g, inv)
+	}
+
+	if rand.Float64() < ne.AddNode {
+		ne.addNode(g, inv)
+	}
+}
+
+// addConnection connects two unconnected nodes, as long as doing so doesn't create a cycle
+func (ne NEAT) addConnection(g *Genome, inv *innovations) {
+	for attempt := 0; attempt < 20; attempt++ {
+		from, to := g.Nodes[rand.Intn(len(g.Nodes))], g.Nodes[rand.Intn(len(g.Nodes))]
+
+		if from.Kind == OutputNode || to.Kind == InputNode || to.Kind == BiasNode || from.ID == to.ID {
+			continue
+		}
+
+		exists := false
+
+		for _, c := range g.Connections {
+			if c.In == from.ID && c.Out == to.ID {
+				exists = true
+				break
+			}
+		}
+
+		if exists || g.reaches(to.ID, from.ID) {
+			continue
+		}
+
+		g.Connections = append(g.Connections, ConnectionGene{
+			In:         from.ID,
+			Out:        to.ID,
+			Weight:     rand.Float64()*2 - 1,
+			Enabled:    true,
+			Innovation: inv.connection(from.ID, to.ID),
+		})
+
+		return
+	}
+}
+
+// addNode splits an enabled connection in two with a new node. The connection into the node has a weight of 1 and the
+// one out of it the old weight, so the genome behaves much as it did before.
+func (ne NEAT) addNode(g *Genome, inv *innovations) {
+	var enabled []int
+
+	for i, c := range g.Connections {
+		if c.Enabled {
+			enabled = append(enabled, i)
+		}
+	}
+
+	if len(enabled) == 0 {
+		return
+	}
+
+	i := enabled[rand.Intn(len(enabled))]
+	old := g.Connections[i]
+	id := inv.split(*g, old.Innovation)
+
+	g.Connections[i].Enabled = false
+	g.Nodes = append(g.Nodes, NodeGene{ID: id, Kind: HiddenNode})
+	g.Connections = append(g.Connections,
+		ConnectionGene{In: old.In, Out: id, Weight: 1, Enabled: true, Innovation: inv.connection(old.In, id)},
+		ConnectionGene{In: id, Out: old.Out, Weight: old.Weight, Enabled: true, Innovation: inv.connection(id, old.Out)},
+	)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"gonum.org/v1/gonum/mat"
+	"io"
 	"io/ioutil"
 	"math/rand"
 	"os"
@@ -13,215 +14,717 @@ import (
 
 // NetworkOptions is for exporting network information to JSON
 type NetworkOptions struct {
+	// Version is the version of the format the file was saved with, which is 0 for files saved before it was recorded
+	Version int `json:",omitempty"`
+
 	I, O   int
 	H      []int
 	Learn  float64
 	WPaths []string
 	BPaths []string
+	Skips  []SkipOptions
+
+	// Inputs, Sizes, From and Outputs describe the layer graph. Files without them hold a simple chain of layers.
+	Inputs  []int
+	Sizes   []int
+	From    []int
+	Outputs []int
+
+	// Custom holds the registered name of each user defined layer, or an empty string for dense layers
+	Custom []string
+
+	// Activations holds the activation of each layer. Files without them use the sigmoid everywhere.
+	Activations []Activation
+
+	// Heads holds the configuration of each output. Files without them use the squared error for every output.
+	Heads []Head
+
+	// Dropout holds the dropout rate of each layer. Files without it don't use dropout.
+	Dropout []float64
+
+	// Temperature is the temperature set by SetTemperature or Calibrate. Files without it use a temperature of 1.
+	Temperature float64
+
+	// Slopes holds the learned slope of each PReLU layer, and 0 for other layers
+	Slopes []float64 `json:",omitempty"`
+
+	// NoBias marks the layers without biases, which have no BPaths. Files without it have biases in every layer.
+	NoBias []bool `json:",omitempty"`
+
+	// Ties holds the layers which use the weights of another layer, which have no WPaths
+	Ties []TieOptions `json:",omitempty"`
 }
 
 // layer is a layer of the network
 type layer struct {
-	weights mat.Matrix
-	biases  mat.Matrix
+	from        int
+	weights     mat.Matrix
+	biases      mat.Matrix
+	skips       []skip
+	custom      Layer
+	activation  Activation
+	slope       mat.Matrix
+	tie         *weightTie // nil unless the layer uses the weights of another layer
+	dropout     float64
+	temperature float64
 }
 
 // newLayer Creates a new layer
 func newLayer(layerSize, inputSize int, random bool) layer {
 	if random {
 		return layer{
-			weights: mat.NewDense(layerSize, inputSize, randomArray(layerSize*inputSize, -1, 1)),
-			biases:  mat.NewDense(layerSize, 1, randomArray(layerSize, -1, 1)),
+			weights:    mat.NewDense(layerSize, inputSize, randomArray(layerSize*inputSize, -1, 1)),
+			biases:     mat.NewDense(layerSize, 1, randomArray(layerSize, -1, 1)),
+			activation: Sigmoid,
 		}
 	}
 
 	return layer{
-		weights: mat.NewDense(layerSize, inputSize, nil),
-		biases:  mat.NewDense(layerSize, 1, nil),
+		weights:    mat.NewDense(layerSize, inputSize, nil),
+		biases:     mat.NewDense(layerSize, 1, nil),
+		activation: Sigmoid,
+	}
+}
+
+// size returns the number of outputs of the layer
+func (l layer) size() int {
+	if l.custom != nil {
+		return l.custom.Size()
+	}
+
+	if l.tie != nil {
+		return l.tie.size
+	}
+
+	r, _ := l.weights.Dims()
+	return r
+}
+
+// params returns the trainable parameters of the layer: its weights unless they are tied to another layer's, its
+// biases if it has them, the weights of each of its projection skips and then its slope if it is a PReLU layer
+func (l layer) params() []mat.Matrix {
+	if l.custom != nil {
+		var params []mat.Matrix
+
+		for _, p := range l.custom.Params() {
+			params = append(params, p)
+		}
+
+		return params
+	}
+
+	var params []mat.Matrix
+
+	if l.tie == nil {
+		params = append(params, l.weights)
+	}
+
+	if l.biases != nil {
+		params = append(params, l.biases)
+	}
+
+	for _, s := range l.skips {
+		if s.weights != nil {
+			params = append(params, s.weights)
+		}
+	}
+
+	if l.slope != nil {
+		params = append(params, l.slope)
+	}
+
+	return params
+}
+
+// setParams replaces the trainable parameters of the layer, given in the same order as params
+func (l *layer) setParams(params []mat.Matrix) {
+	if l.custom != nil {
+		for j, p := range l.custom.Params() {
+			p.Copy(params[j])
+		}
+
+		return
+	}
+
+	if l.tie == nil {
+		l.weights, params = params[0], params[1:]
+	}
+
+	if l.biases != nil {
+		l.biases, params = params[0], params[1:]
+	}
+
+	for j := range l.skips {
+		if l.skips[j].weights != nil {
+			l.skips[j].weights, params = params[0], params[1:]
+		}
+	}
+
+	if l.slope != nil {
+		l.slope = params[0]
 	}
 }
 
-// Network contains the whole neural network
+// weightStep is the fraction of the learning rate that weights move by. Weights have always taken half the step of
+// biases, which is kept so that training behaves the same as it always has.
+const weightStep = 0.5
+
+// steps returns the fraction of the learning rate each of the layer's params moves by, in the same order as params
+func (l layer) steps() []float64 {
+	res := make([]float64, len(l.params()))
+
+	for j := range res {
+		res[j] = 1
+	}
+
+	if l.custom != nil {
+		return res
+	}
+
+	j := 0
+
+	if l.tie == nil {
+		res[j] = weightStep
+		j++
+	}
+
+	if l.biases != nil {
+		j++
+	}
+
+	for _, s := range l.skips {
+		if s.weights != nil {
+			res[j] = weightStep
+			j++
+		}
+	}
+
+	return res
+}
+
+// Network contains the whole neural network. Its layers form a directed acyclic graph where every layer reads from
+// inputs or layers that come before it. Inputs and outputs are referred to by activation index: activations
+// 0 to len(inputs)-1 are the inputs and the activations after them are the outputs of each layer in order.
+//
+// Evaluating a network only ever reads its weights, so Calc and the other methods that don't change the network are
+// safe for concurrent use, as long as nothing trains or otherwise modifies it at the same time. Training replaces
+// weight matrices rather than changing them in place, so even a View taken before training continues to see the old
+// weights and can keep serving.
 type Network struct {
 	i, o, h   int
-	hidden    []int
+	inputs    []int
+	outputs   []int
+	heads     []head
 	layers    []layer
 	learnRate float64
+	hooks     []Hook
+	assets    map[string][]byte
+
+	quantize    bool
+	fastSigmoid bool
 }
 
-// NewNetwork Creates a new Network
+// NewNetwork Creates a new Network made of a simple chain of layers
 func NewNetwork(inputs, outputs int, hidden []int, learn float64, random bool) Network {
-	layers := make([]layer, len(hidden)+1)
+	g := NewGraph()
+	a := g.Input(inputs)
 
-	for i := 0; i < len(hidden)+1; i++ {
-		if i == 0 {
-			layers[i] = newLayer(hidden[i], inputs, random)
-			continue
+	for _, size := range hidden {
+		a = g.Dense(size, a)
+	}
+
+	n, err := g.Network([]int{g.Dense(outputs, a)}, learn, random)
+	if err != nil {
+		panic(err)
+	}
+
+	return n
+}
+
+// Dims returns the total size of the network's inputs and outputs
+func (n Network) Dims() (inputs, outputs int) {
+	return n.i, n.o
+}
+
+// LearnRate returns the network's learning rate
+func (n Network) LearnRate() float64 {
+	return n.learnRate
+}
+
+// SetLearnRate changes the network's learning rate
+func (n *Network) SetLearnRate(rate float64) {
+	n.learnRate = rate
+}
+
+// forward evaluates the network, returning the weighted input of every layer and every activation
+func (n Network) forward(inputs []mat.Matrix) (zs, activations []mat.Matrix) {
+	zs, activations, _ = n.forwardDropout(inputs, false)
+	return zs, activations
+}
+
+// forwardDropout evaluates the network like forward, and when dropout is true also drops out the outputs of layers
+// with a dropout rate. It returns the mask each of those outputs was multiplied by, which backward needs.
+func (n Network) forwardDropout(inputs []mat.Matrix, dropout bool) (zs, activations, masks []mat.Matrix) {
+	zs = make([]mat.Matrix, n.h)
+	activations = make([]mat.Matrix, len(n.inputs)+n.h)
+	copy(activations, inputs)
+
+	if dropout {
+		masks = make([]mat.Matrix, n.h)
+	}
+
+	for i := 0; i < n.h; i++ {
+		l := n.layers[i]
+		a := len(n.inputs) + i
+
+		if l.custom != nil {
+			activations[a] = l.custom.Forward(activations[l.from])
+		} else {
+			weights := n.weights(i)
+
+			if n.quantize {
+				weights = fakeQuantize(weights)
+			}
+
+			zs[i] = dot(weights, activations[l.from])
+
+			if l.biases != nil {
+				zs[i] = add(zs[i], l.biases)
+			}
+
+			for _, s := range l.skips {
+				zs[i] = add(zs[i], s.forward(activations[s.from]))
+			}
+
+			if l.temperature > 0 {
+				zs[i] = scl(1/l.temperature, zs[i])
+			}
+
+			if n.fastSigmoid && !dropout && l.activation == Sigmoid {
+				activations[a] = fun(fastSigmoid, zs[i])
+			} else {
+				activations[a] = l.activate(zs[i])
+			}
 		}
 
-		if i == len(hidden) {
-			layers[i] = newLayer(outputs, hidden[i-1], random)
-			continue
+		if n.quantize {
+			activations[a] = fakeQuantizeActivations(activations[a])
 		}
 
-		layers[i] = newLayer(hidden[i], hidden[i-1], random)
+		if dropout && l.dropout > 0 {
+			masks[i] = dropoutMask(l.size(), l.dropout)
+			activations[a] = mul(activations[a], masks[i])
+		}
+
+		n.runHooks(i, zs[i], activations[a])
 	}
 
-	return Network{
-		i:         inputs,
-		h:         len(layers),
-		o:         outputs,
-		hidden:    hidden,
-		layers:    layers,
-		learnRate: learn,
+	return zs, activations, masks
+}
+
+// split cuts concatenated data into one column vector per given size
+func split(data []float64, sizes []int) []mat.Matrix {
+	res := make([]mat.Matrix, len(sizes))
+
+	for i, size := range sizes {
+		res[i] = mat.NewDense(size, 1, data[:size])
+		data = data[size:]
 	}
+
+	return res
+}
+
+// outputSizes returns the size of each of the network's outputs
+func (n Network) outputSizes() []int {
+	sizes := make([]int, len(n.outputs))
+
+	for i, a := range n.outputs {
+		sizes[i] = n.size(a)
+	}
+
+	return sizes
 }
 
-// Calc evaluates a given input into the network
+// Calc evaluates a given input into the network. If the network has several inputs or outputs they are concatenated
+// in the order they were given when building it. Calc is safe for concurrent use.
 func (n Network) Calc(data []float64) []float64 {
-	if len(data) != n.i {
-		panic(errInvalidDataSize)
+	if err := n.checkInput(data); err != nil {
+		panic(err)
 	}
 
-	inputs := mat.NewDense(n.i, 1, data)
+	_, activations := n.forward(split(data, n.inputs))
 
-	var activation mat.Matrix
+	return n.outputValues(activations)
+}
 
-	for i := 0; i < n.h; i++ {
-		if i == 0 {
-			activation = fun(sigmoid, add(dot(n.layers[i].weights, inputs), n.layers[i].biases))
-			continue
+// outputValues concatenates the network's outputs from a set of activations
+func (n Network) outputValues(activations []mat.Matrix) []float64 {
+	res := make([]float64, 0, n.o)
+
+	for _, a := range n.outputs {
+		r, _ := activations[a].Dims()
+
+		for i := 0; i < r; i++ {
+			res = append(res, activations[a].At(i, 0))
 		}
+	}
 
-		activation = fun(sigmoid, add(dot(n.layers[i].weights, activation), n.layers[i].biases))
+	return res
+}
+
+// CalcHeads evaluates one slice of data per input of the network, returning one slice per output
+func (n Network) CalcHeads(data ...[]float64) [][]float64 {
+	if len(data) != len(n.inputs) {
+		panic(DimensionError{Arg: "data", Unit: "inputs", Got: len(data), Want: len(n.inputs)})
 	}
 
-	r, _ := activation.Dims()
-	res := make([]float64, r)
+	var joined []float64
+
+	for i, d := range data {
+		if len(d) != n.inputs[i] {
+			panic(DimensionError{Arg: fmt.Sprintf("input %d", i), Unit: "features", Got: len(d), Want: n.inputs[i]})
+		}
 
-	for i := 0; i < r; i++ {
-		res[i] = activation.At(i, 0)
+		joined = append(joined, d...)
 	}
 
-	return res
+	res := n.Calc(joined)
+	heads := make([][]float64, len(n.outputs))
+
+	for i, size := range n.outputSizes() {
+		heads[i] = res[:size]
+		res = res[size:]
+	}
+
+	return heads
 }
 
-// backpropagate performs a small change on the network based on given data
-func (n *Network) backpropagate(inputData []float64, expectedData []float64) {
-	if len(inputData) != n.i || len(expectedData) != n.o {
-		panic(errInvalidDataSize)
+// Gradients runs a forward and backward pass for a single sample and returns the gradient of its cost with respect to
+// every parameter of the network, without applying them. Layers with a dropout rate are dropped out as in training.
+func (n Network) Gradients(inputData []float64, expectedData []float64) Gradients {
+	if err := n.checkSample(inputData, expectedData); err != nil {
+		panic(err)
 	}
 
-	input := mat.NewDense(n.i, 1, inputData)
-	expected := mat.NewDense(n.o, 1, expectedData)
+	zs, activations, masks := n.forwardDropout(split(inputData, n.inputs), true)
+	expected := split(expectedData, n.outputSizes())
+	outputGrads := make([]mat.Matrix, len(n.outputs))
 
-	var (
-		activations = make([]mat.Matrix, n.h)
-		zs          = make([]mat.Matrix, n.h)
-	)
+	for i, a := range n.outputs {
+		outputGrads[i] = scl(n.heads[i].weight, n.heads[i].loss.grad(activations[a], expected[i]))
+	}
 
-	for i := 0; i < n.h; i++ {
-		if i == 0 {
-			zs[i] = add(dot(n.layers[i].weights, input), n.layers[i].biases)
-			activations[i] = fun(sigmoid, zs[i])
+	grads, _ := n.backward(zs, activations, masks, outputGrads)
+
+	return grads
+}
+
+// backward carries the gradient of the cost with respect to each output back through the network, returning the
+// gradients of every parameter and of every activation. Outputs with a nil gradient are ignored, and masks holds the
+// dropout masks returned by forwardDropout, if any.
+func (n Network) backward(zs, activations, masks, outputGrads []mat.Matrix) (Gradients, []mat.Matrix) {
+	// layerErrors[a] accumulates the gradient of activation a from every layer it feeds into
+	layerErrors := make([]mat.Matrix, len(activations))
+	grads := make(Gradients, n.h)
+
+	// tied[i] accumulates the gradients of layers tied to layer i, which are added to the gradient of its weights
+	tied := make([]mat.Matrix, n.h)
+
+	for i, a := range n.outputs {
+		if outputGrads[i] != nil {
+			layerErrors[a] = accumulate(layerErrors[a], outputGrads[i])
+		}
+	}
+
+	for i := n.h - 1; i >= 0; i-- {
+		l := n.layers[i]
+		a := len(n.inputs) + i
+
+		if layerErrors[a] == nil {
+			continue
+		}
+
+		if masks != nil && masks[i] != nil {
+			layerErrors[a] = mul(layerErrors[a], masks[i])
+		}
+
+		if l.custom != nil {
+			var inputGrad mat.Matrix
+
+			inputGrad, grads[i] = l.custom.Backward(activations[l.from], layerErrors[a])
+			layerErrors[l.from] = accumulate(layerErrors[l.from], inputGrad)
 			continue
 		}
 
-		zs[i] = add(dot(n.layers[i].weights, activations[i-1]), n.layers[i].biases)
-		activations[i] = fun(sigmoid, zs[i])
+		delta, slope := l.deactivate(zs[i], activations[a], layerErrors[a])
+
+		if l.temperature > 0 {
+			delta = scl(1/l.temperature, delta)
+		}
+		weights := dot(delta, activations[l.from].T())
+
+		switch {
+		case l.tie == nil:
+			grads[i] = []mat.Matrix{accumulate(tied[i], weights)}
+		case l.tie.transpose:
+			tied[l.tie.source] = accumulate(tied[l.tie.source], weights.T())
+			grads[i] = []mat.Matrix{}
+		default:
+			tied[l.tie.source] = accumulate(tied[l.tie.source], weights)
+			grads[i] = []mat.Matrix{}
+		}
+
+		if l.biases != nil {
+			grads[i] = append(grads[i], delta)
+		}
+
+		layerErrors[l.from] = accumulate(layerErrors[l.from], dot(n.weights(i).T(), delta))
+
+		for _, s := range l.skips {
+			layerErrors[s.from] = accumulate(layerErrors[s.from], s.backward(delta))
+
+			if s.weights != nil {
+				grads[i] = append(grads[i], dot(delta, activations[s.from].T()))
+			}
+		}
+
+		if slope != nil {
+			grads[i] = append(grads[i], slope)
+		}
 	}
 
-	layerErrors := sub(expected, activations[n.h-1])
+	// Layers which only affect the cost through the layers tied to them get zero gradients for their other parameters
+	for i, g := range tied {
+		if g == nil || grads[i] != nil {
+			continue
+		}
 
-	for i := n.h - 1; i >= 0; i-- {
-		if i != n.h-1 {
-			layerErrors = dot(n.layers[i+1].weights.T(), layerErrors)
-		}
-
-		n.layers[i].biases = add(n.layers[i].biases,
-			scl(2*n.learnRate,
-				mul(
-					layerErrors,
-					fun(dSigmoid, zs[i]))))
-
-		if i == 0 {
-			n.layers[i].weights = add(n.layers[i].weights,
-				scl(n.learnRate,
-					dot(mul(
-						layerErrors,
-						fun(dSigmoid, zs[i])),
-						input.T())))
+		for _, p := range n.layers[i].params() {
+			r, c := p.Dims()
+			grads[i] = append(grads[i], mat.NewDense(r, c, nil))
+		}
+
+		grads[i][0] = g
+	}
+
+	return grads, layerErrors
+}
+
+// ApplyGradients moves every parameter of the network against its gradient, scaled by rate. The weights of dense
+// layers and projection skips move by half as much as their biases and every other parameter.
+func (n *Network) ApplyGradients(grads Gradients, rate float64) {
+	for i := range n.layers {
+		if grads[i] == nil {
 			continue
 		}
 
-		n.layers[i].weights = add(n.layers[i].weights,
-			scl(n.learnRate,
-				dot(mul(
-					layerErrors,
-					fun(dSigmoid, zs[i])),
-					activations[i-1].T())))
+		params, steps := n.layers[i].params(), n.layers[i].steps()
+
+		for j := range params {
+			params[j] = sub(params[j], scl(rate*steps[j], grads[i][j]))
+		}
+
+		n.layers[i].setParams(params)
 	}
 }
 
+// backpropagate performs a small change on the network based on given data
+func (n *Network) backpropagate(inputData []float64, expectedData []float64) {
+	n.ApplyGradients(n.Gradients(inputData, expectedData), n.learnRate)
+}
+
 // Train repeatedly performs backpropagation. Will print information on the performance of the network
-func (n *Network) Train(inputs, expected [][]float64, epochs int) {
-	if len(inputs) != len(expected) {
-		panic(errInvalidDataSize)
+func (n *Network) Train(inputs, expected [][]float64, epochs int) Report {
+	report, err := n.TrainWith(inputs, expected, TrainOptions{Epochs: epochs})
+	if err != nil {
+		panic(err)
 	}
 
-	fmt.Printf("Began training for %d epochs...\n", epochs)
+	return report
+}
 
-	start := time.Now()
+func (n *Network) Perturb(strength float64) {
+	rand.Seed(time.Now().Unix())
 
-	for epoch := 0; epoch < epochs; epoch++ {
-		counter := time.Now()
-		avgCost := 0.0
+	for i := range n.layers {
+		params := n.layers[i].params()
 
-		for i := 0; i < len(inputs); i++ {
-			n.backpropagate(inputs[i], expected[i])
-			avgCost += totalCost(expected[i], n.Calc(inputs[i]))
+		for j, p := range params {
+			r, c := p.Dims()
+			params[j] = add(p, mat.NewDense(r, c, randomArray(r*c, -1*strength, 1*strength)))
 		}
 
-		avgCost /= float64(len(inputs))
-
-		fmt.Printf("  + Completed epoch %d of %d in %dms with an average cost of %.5f,\n",
-			epoch+1, epochs, time.Since(counter).Milliseconds(), avgCost)
+		n.layers[i].setParams(params)
 	}
+}
 
-	delta := time.Since(start).Milliseconds()
+// Copy returns a copy of the network with its own weight matrices. The parameters of custom layers are updated in
+// place and are still shared, use Clone for a copy which shares nothing.
+func (n *Network) Copy() Network {
+	m := n.View()
 
-	fmt.Printf("Trained for %d epochs in %dms with an average of %dms per epoch.\n",
-		epochs, delta, delta/int64(epochs))
+	for i, l := range m.layers {
+		if l.custom != nil {
+			continue
+		}
+
+		if l.weights != nil {
+			m.layers[i].weights = mat.DenseCopyOf(l.weights)
+		}
+
+		if l.biases != nil {
+			m.layers[i].biases = mat.DenseCopyOf(l.biases)
+		}
+
+		for j, s := range l.skips {
+			if s.weights != nil {
+				m.layers[i].skips[j].weights = mat.DenseCopyOf(s.weights)
+			}
+		}
+
+		if l.slope != nil {
+			m.layers[i].slope = mat.DenseCopyOf(l.slope)
+		}
+	}
+
+	return m
 }
 
-func (n *Network) Perturb(strength float64) {
-	rand.Seed(time.Now().Unix())
+// Clone returns a copy of the network which shares nothing with it, including the parameters of custom layers. Custom
+// layers are copied by marshaling them, so their types must be registered with RegisterLayer.
+func (n *Network) Clone() (Network, error) {
+	m := n.Copy()
 
-	for i := 0; i < n.h; i++ {
-		wr, wc := n.layers[i].weights.Dims()
-		br, bc := n.layers[i].biases.Dims()
+	for i, l := range m.layers {
+		if l.custom == nil {
+			continue
+		}
+
+		c, err := cloneLayer(l.custom)
+		if err != nil {
+			return Network{}, err
+		}
 
-		n.layers[i].weights = add(n.layers[i].weights, mat.NewDense(wr, wc, randomArray(wr*wc, -1*strength, 1*strength)))
-		n.layers[i].biases = add(n.layers[i].biases, mat.NewDense(br, bc, randomArray(br*bc, -1*strength, 1*strength)))
+		m.layers[i].custom = c
 	}
+
+	return m, nil
 }
 
-func (n *Network) Copy() (m Network) {
+// View returns a copy of the network's structure which shares its weight matrices. It is cheap to take, and as
+// training replaces dense weights rather than changing them it keeps seeing the weights the network had when it was
+// taken, except for those of custom layers.
+func (n *Network) View() (m Network) {
 	m = Network{
 		i:         n.i,
 		o:         n.o,
 		h:         n.h,
-		hidden:    make([]int, len(n.hidden)),
+		inputs:    make([]int, len(n.inputs)),
+		outputs:   make([]int, len(n.outputs)),
+		heads:     make([]head, len(n.heads)),
 		layers:    make([]layer, len(n.layers)),
 		learnRate: n.learnRate,
+		hooks:     make([]Hook, len(n.hooks)),
+
+		quantize:    n.quantize,
+		fastSigmoid: n.fastSigmoid,
 	}
 
-	copy(m.hidden, n.hidden)
+	copy(m.inputs, n.inputs)
+	copy(m.outputs, n.outputs)
+	copy(m.heads, n.heads)
 	copy(m.layers, n.layers)
+	copy(m.hooks, n.hooks)
+
+	if len(n.assets) > 0 {
+		m.assets = make(map[string][]byte, len(n.assets))
+
+		for name, data := range n.assets {
+			m.assets[name] = data
+		}
+	}
+
+	for i := range m.layers {
+		m.layers[i].skips = make([]skip, len(n.layers[i].skips))
+		copy(m.layers[i].skips, n.layers[i].skips)
+	}
 
 	return m
 }
 
+// options describes the network's structure for saving, along with the paths its weights are saved under by Save
+func (n Network) options() NetworkOptions {
+	opts := NetworkOptions{
+		Version: formatVersion,
+		I:       n.i,
+		O:       n.o,
+		Learn:   n.learnRate,
+		WPaths:  make([]string, n.h),
+		BPaths:  make([]string, n.h),
+		Inputs:  n.inputs,
+		Sizes:   make([]int, n.h),
+		From:    make([]int, n.h),
+		Outputs: n.outputs,
+		Custom:  make([]string, n.h),
+
+		Activations: make([]Activation, n.h),
+		Heads:       n.Heads(),
+		Dropout:     make([]float64, n.h),
+		Temperature: n.Temperature(),
+		Ties:        n.Ties(),
+	}
+
+	for i := 0; i < n.h; i++ {
+		opts.Dropout[i] = n.layers[i].dropout
+
+		if n.layers[i].slope != nil {
+			if opts.Slopes == nil {
+				opts.Slopes = make([]float64, n.h)
+			}
+
+			opts.Slopes[i] = n.layers[i].slope.At(0, 0)
+		}
+
+		if n.layers[i].custom != nil {
+			opts.WPaths[i] = fmt.Sprintf("%dl.bin", i)
+			opts.Sizes[i] = n.size(len(n.inputs) + i)
+			opts.From[i] = n.layers[i].from
+			opts.Custom[i] = n.layers[i].custom.Name()
+			continue
+		}
+
+		if n.layers[i].tie == nil {
+			opts.WPaths[i] = fmt.Sprintf("%dw.bin", i)
+		}
+
+		if n.layers[i].biases != nil {
+			opts.BPaths[i] = fmt.Sprintf("%db.bin", i)
+		} else {
+			if opts.NoBias == nil {
+				opts.NoBias = make([]bool, n.h)
+			}
+
+			opts.NoBias[i] = true
+		}
+
+		opts.Activations[i] = n.layers[i].activation
+		opts.Sizes[i] = n.size(len(n.inputs) + i)
+		opts.From[i] = n.layers[i].from
+
+		for j, sk := range n.layers[i].skips {
+			so := SkipOptions{From: sk.from, To: len(n.inputs) + i}
+
+			if sk.weights != nil {
+				so.Path = fmt.Sprintf("%ds%d.bin", i, j)
+			}
+
+			opts.Skips = append(opts.Skips, so)
+		}
+	}
+
+	return opts
+}
+
 // Save will compress the network and then save it as a file to be used later.
 func (n Network) Save(filename string) error {
 	file, err := os.Create(filename)
@@ -229,23 +732,21 @@ func (n Network) Save(filename string) error {
 		return err
 	}
 
-	zipper := zip.NewWriter(file)
+	if err = n.writeZip(file); err != nil {
+		_ = file.Close()
+		return err
+	}
 
-	meta, err := zipper.Create("meta.json")
+	return file.Close()
+}
 
-	opts := NetworkOptions{
-		I:      n.i,
-		O:      n.o,
-		H:      n.hidden,
-		Learn:  n.learnRate,
-		WPaths: make([]string, n.h),
-		BPaths: make([]string, n.h),
-	}
+// writeZip writes the archive saved by Save
+func (n Network) writeZip(w io.Writer) error {
+	zipper := zip.NewWriter(w)
 
-	for i := 0; i < n.h; i++ {
-		opts.WPaths[i] = fmt.Sprintf("%dw.bin", i)
-		opts.BPaths[i] = fmt.Sprintf("%db.bin", i)
-	}
+	meta, err := zipper.Create("meta.json")
+
+	opts := n.options()
 
 	metaJson, err := json.Marshal(opts)
 
@@ -255,50 +756,98 @@ func (n Network) Save(filename string) error {
 	}
 
 	for i := 0; i < n.h; i++ {
-		w, wErr := zipper.Create(fmt.Sprintf("%dw.bin", i))
-		if wErr != nil {
-			return wErr
+		if n.layers[i].custom != nil {
+			c, cErr := zipper.Create(opts.WPaths[i])
+			if cErr != nil {
+				return cErr
+			}
+
+			cb, cErr := n.layers[i].custom.MarshalBinary()
+			if cErr != nil {
+				return cErr
+			}
+
+			_, cErr = c.Write(cb)
+			if cErr != nil {
+				return cErr
+			}
+
+			continue
 		}
 
-		wb, wErr := n.layers[i].weights.(*mat.Dense).MarshalBinary()
-		if wErr != nil {
-			return wErr
+		if opts.WPaths[i] != "" {
+			w, wErr := zipper.Create(opts.WPaths[i])
+			if wErr != nil {
+				return wErr
+			}
+
+			wb, wErr := n.layers[i].weights.(*mat.Dense).MarshalBinary()
+			if wErr != nil {
+				return wErr
+			}
+
+			_, wErr = w.Write(wb)
+			if wErr != nil {
+				return wErr
+			}
 		}
 
-		_, wErr = w.Write(wb)
-		if wErr != nil {
-			return wErr
+		if opts.BPaths[i] != "" {
+			b, bErr := zipper.Create(opts.BPaths[i])
+			if bErr != nil {
+				return bErr
+			}
+
+			bb, bErr := n.layers[i].biases.(*mat.Dense).MarshalBinary()
+			if bErr != nil {
+				return bErr
+			}
+
+			_, bErr = b.Write(bb)
+			if bErr != nil {
+				return bErr
+			}
 		}
 
-		b, bErr := zipper.Create(fmt.Sprintf("%db.bin", i))
-		if bErr != nil {
-			return bErr
+		for j, sk := range n.layers[i].skips {
+			if sk.weights == nil {
+				continue
+			}
+
+			s, sErr := zipper.Create(fmt.Sprintf("%ds%d.bin", i, j))
+			if sErr != nil {
+				return sErr
+			}
+
+			sb, sErr := sk.weights.(*mat.Dense).MarshalBinary()
+			if sErr != nil {
+				return sErr
+			}
+
+			_, sErr = s.Write(sb)
+			if sErr != nil {
+				return sErr
+			}
 		}
+	}
 
-		bb, bErr := n.layers[i].biases.(*mat.Dense).MarshalBinary()
-		if bErr != nil {
-			return bErr
+	for _, name := range n.Assets() {
+		a, aErr := zipper.Create(assetPrefix + name)
+		if aErr != nil {
+			return aErr
 		}
 
-		_, bErr = b.Write(bb)
-		if bErr != nil {
-			return bErr
+		_, aErr = a.Write(n.assets[name])
+		if aErr != nil {
+			return aErr
 		}
 	}
 
-	_ = zipper.Close()
-	_ = file.Close()
-
-	return nil
+	return zipper.Close()
 }
 
-// Load will open a saved network
-func Load(filename string) (n Network, err error) {
-	zipFile, err := zip.OpenReader(filename)
-	if err != nil {
-		return Network{}, err
-	}
-
+// loadZip reads a network saved by Save
+func loadZip(zipFile *zip.Reader) (n Network, err error) {
 	metaFile, err := zipFile.Open("meta.json")
 	if err != nil {
 		return Network{}, err
@@ -316,39 +865,161 @@ func Load(filename string) (n Network, err error) {
 		return Network{}, err
 	}
 
-	n = NewNetwork(opts.I, opts.O, opts.H, opts.Learn, false)
-
 	_ = metaFile.Close()
 
+	if err = checkVersion(opts.Version); err != nil {
+		return Network{}, err
+	}
+
+	// Every layer is stored in its own file, so they are read and decoded in parallel
+	customs := make([]Layer, len(opts.Sizes))
+	jobs := make([]func() error, 0, len(opts.Custom))
+
+	for i, name := range opts.Custom {
+		if name == "" {
+			continue
+		}
+
+		i, name := i, name
+
+		jobs = append(jobs, func() (err error) {
+			customs[i], err = loadCustomLayer(zipFile, name, opts.WPaths[i])
+			return err
+		})
+	}
+
+	if err = parallel(jobs); err != nil {
+		return Network{}, err
+	}
+
+	n, err = opts.network(customs, false)
+	if err != nil {
+		return Network{}, err
+	}
+
+	jobs = jobs[:0]
+
 	for i := 0; i < n.h; i++ {
-		w, wErr := zipFile.Open(fmt.Sprintf("%s", opts.WPaths[i]))
-		if wErr != nil {
-			return Network{}, wErr
+		if n.layers[i].custom != nil {
+			continue
 		}
 
-		n.layers[i].weights.(*mat.Dense).Reset()
-		_, wErr = n.layers[i].weights.(*mat.Dense).UnmarshalBinaryFrom(w)
-		if wErr != nil {
-			return Network{}, wErr
+		if n.layers[i].weights != nil {
+			jobs = append(jobs, loadMatrix(zipFile, opts.WPaths[i], n.layers[i].weights.(*mat.Dense)))
 		}
 
-		_ = w.Close()
+		if n.layers[i].biases != nil {
+			jobs = append(jobs, loadMatrix(zipFile, opts.BPaths[i], n.layers[i].biases.(*mat.Dense)))
+		}
+	}
+
+	// network adds the skips of each layer in the order they are listed
+	added := make([]int, n.h)
 
-		b, bErr := zipFile.Open(fmt.Sprintf("%s", opts.BPaths[i]))
-		if bErr != nil {
-			return Network{}, bErr
+	for _, so := range opts.Skips {
+		l := &n.layers[so.To-len(n.inputs)]
+		sk := &l.skips[added[so.To-len(n.inputs)]]
+		added[so.To-len(n.inputs)]++
+
+		if so.Path == "" {
+			continue
 		}
 
-		n.layers[i].biases.(*mat.Dense).Reset()
-		_, bErr = n.layers[i].biases.(*mat.Dense).UnmarshalBinaryFrom(b)
-		if bErr != nil {
-			return Network{}, bErr
+		jobs = append(jobs, loadMatrix(zipFile, so.Path, sk.weights.(*mat.Dense)))
+	}
+
+	if err = parallel(jobs); err != nil {
+		return Network{}, err
+	}
+
+	err = loadAssets(zipFile, &n)
+	if err != nil {
+		return Network{}, err
+	}
+
+	return n, nil
+}
+
+// loadMatrix returns a function which reads a matrix saved by Save from the archive into m
+func loadMatrix(zipFile *zip.Reader, path string, m *mat.Dense) func() error {
+	return func() error {
+		f, err := zipFile.Open(path)
+		if err != nil {
+			return err
+		}
+
+		m.Reset()
+		_, err = m.UnmarshalBinaryFrom(f)
+		_ = f.Close()
+
+		return err
+	}
+}
+
+// network builds the network described by the options, with random or zeroed weights. customs holds the user defined
+// layers, which must already be filled in.
+func (opts NetworkOptions) network(customs []Layer, random bool) (n Network, err error) {
+	if len(opts.Sizes) == 0 {
+		n = NewNetwork(opts.I, opts.O, opts.H, opts.Learn, random)
+	} else {
+		n, err = opts.graph(customs).Network(opts.Outputs, opts.Learn, random)
+		if err != nil {
+			return Network{}, err
+		}
+	}
+
+	for i, a := range opts.Activations {
+		if i >= n.h || n.layers[i].custom != nil {
+			continue
 		}
 
-		_ = b.Close()
+		if !a.valid() {
+			return Network{}, errUnknownActivation
+		}
+
+		n.layers[i].setActivation(a)
+	}
+
+	for i, slope := range opts.Slopes {
+		if i < n.h && n.layers[i].slope != nil {
+			n.layers[i].slope = mat.NewDense(1, 1, []float64{slope})
+		}
 	}
 
-	_ = zipFile.Close()
+	for i, rate := range opts.Dropout {
+		err = n.SetDropout(i, rate)
+		if err != nil {
+			return Network{}, err
+		}
+	}
+
+	for i, h := range opts.Heads {
+		err = n.SetHead(i, h)
+		if err != nil {
+			return Network{}, err
+		}
+	}
+
+	if opts.Temperature != 0 {
+		err = n.SetTemperature(opts.Temperature)
+		if err != nil {
+			return Network{}, err
+		}
+	}
+
+	for _, so := range opts.Skips {
+		err = n.AddSkip(so.From, so.To, so.Path != "")
+		if err != nil {
+			return Network{}, err
+		}
+	}
+
+	for _, t := range opts.Ties {
+		err = n.TieWeights(t.Layer, t.Source, t.Transpose)
+		if err != nil {
+			return Network{}, err
+		}
+	}
 
 	return n, nil
 }
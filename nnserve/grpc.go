@@ -0,0 +1,87 @@
+package nnserve
+
+import (
+	"context"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+//go:generate protoc --go_out=.. --go_opt=module=github.com/e74000/nn --go-grpc_out=.. --go-grpc_opt=module=github.com/e74000/nn nnserve.proto
+
+// predictor implements the Predictor service for a Server
+type predictor struct {
+	UnimplementedPredictorServer
+
+	s *Server
+}
+
+// GRPCServer returns a gRPC server with the Predictor service described in nnserve.proto registered, serving the same
+// network as s. Requests are limited to the same size as the JSON handlers accept. Serve it on a listener of its own.
+func (s *Server) GRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	g := grpc.NewServer(append([]grpc.ServerOption{grpc.MaxRecvMsgSize(maxMessageSize)}, opts...)...)
+	RegisterPredictorServer(g, predictor{s: s})
+
+	return g
+}
+
+// Predict implements PredictorServer
+func (p predictor) Predict(_ context.Context, req *PredictRequest) (res *PredictResponse, err error) {
+	start := time.Now()
+	defer func() { p.s.metrics.observe(start, err != nil) }()
+
+	snapshot := p.s.model.Load()
+
+	if err = checkInput(snapshot, req.Input); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	p.s.metrics.predicted(1)
+	return &PredictResponse{Output: snapshot.Calc(req.Input)}, nil
+}
+
+// BatchPredict implements PredictorServer
+func (p predictor) BatchPredict(_ context.Context, req *BatchPredictRequest) (res *BatchPredictResponse, err error) {
+	start := time.Now()
+	defer func() { p.s.metrics.observe(start, err != nil) }()
+
+	snapshot := p.s.model.Load()
+	res = &BatchPredictResponse{Outputs: make([]*PredictResponse, len(req.Inputs))}
+
+	for i, input := range req.Inputs {
+		if err = checkInput(snapshot, input.Input); err != nil {
+			return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("input %d: %s", i, err))
+		}
+
+		res.Outputs[i] = &PredictResponse{Output: snapshot.Calc(input.Input)}
+	}
+
+	p.s.metrics.predicted(len(res.Outputs))
+	return res, nil
+}
+
+// ModelInfo implements PredictorServer
+func (p predictor) ModelInfo(context.Context, *ModelInfoRequest) (*ModelInfoResponse, error) {
+	snapshot := p.s.model.Load()
+	n := snapshot.Network()
+	inputs, outputs := n.Dims()
+
+	res := &ModelInfoResponse{
+		Inputs:      int32(inputs),
+		Outputs:     int32(outputs),
+		Fingerprint: snapshot.Fingerprint(),
+	}
+
+	for _, h := range n.Heads() {
+		res.Heads = append(res.Heads, &Head{
+			Size:       int32(h.Size),
+			Activation: h.Activation.Name,
+			Loss:       h.Loss.Name,
+			Weight:     h.Weight,
+		})
+	}
+
+	return res, nil
+}
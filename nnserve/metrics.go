@@ -0,0 +1,96 @@
+package nnserve
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"time"
+)
+
+// latencyBuckets are the bucket bounds, in seconds, of the request latency histogram
+var latencyBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+// metrics counts the work done by a Server. QPS is left to Prometheus, as the rate of nn_serve_requests_total.
+type metrics struct {
+	requests    prometheus.Counter
+	predictions prometheus.Counter
+	errors      prometheus.Counter
+	latency     prometheus.Histogram
+
+	handler http.Handler
+}
+
+// newMetrics Creates an empty set of metrics, registered with a registry of their own so that every Server can serve
+// its own
+func newMetrics() *metrics {
+	m := &metrics{
+		requests: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "nn_serve_requests_total",
+			Help: "Prediction requests handled.",
+		}),
+		predictions: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "nn_serve_predictions_total",
+			Help: "Inputs evaluated.",
+		}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "nn_serve_errors_total",
+			Help: "Prediction requests that failed.",
+		}),
+		latency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "nn_serve_request_duration_seconds",
+			Help:    "Time taken to handle each prediction request.",
+			Buckets: latencyBuckets,
+		}),
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(m.requests, m.predictions, m.errors, m.latency)
+	m.handler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+
+	return m
+}
+
+// observe records a finished prediction request
+func (m *metrics) observe(start time.Time, failed bool) {
+	m.requests.Inc()
+
+	if failed {
+		m.errors.Inc()
+	}
+
+	m.latency.Observe(time.Since(start).Seconds())
+}
+
+// predicted records that a number of inputs have been evaluated
+func (m *metrics) predicted(inputs int) {
+	m.predictions.Add(float64(inputs))
+}
+
+// instrument records the requests handled by h, counting any response with an error status as failed
+func (m *metrics) instrument(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+		h(sw, r)
+
+		m.observe(start, sw.status >= http.StatusBadRequest)
+	}
+}
+
+// statusWriter remembers the status code written to a response
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implements http.ResponseWriter
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format
+func (m *metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.handler.ServeHTTP(w, r)
+}
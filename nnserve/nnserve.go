@@ -0,0 +1,192 @@
+// Package nnserve serves predictions from a saved network over HTTP.
+//
+// The handler responds to:
+//
+//	POST /predict        {"input": [...]}    -> {"output": [...]}
+//	POST /predict/batch  {"inputs": [[...]]} -> {"outputs": [[...]]}
+//	GET  /health                             -> {"status": "ok"}
+//	GET  /info                               -> {"inputs": 4, "outputs": 3, "heads": [...], "fingerprint": "..."}
+//	GET  /metrics                            -> request counts and latencies in the Prometheus text format
+//
+// The same predictions are available over gRPC from GRPCServer, using the Predictor service in nnserve.proto.
+package nnserve
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/e74000/nn"
+	"net/http"
+)
+
+// maxMessageSize is the largest request body the JSON handlers accept and the largest message accepted over gRPC, the
+// same as the default limit of gRPC servers
+const maxMessageSize = 4 << 20
+
+// Info describes the model being served
+type Info struct {
+	Inputs  int       `json:"inputs"`
+	Outputs int       `json:"outputs"`
+	Heads   []nn.Head `json:"heads"`
+
+	// Fingerprint identifies the exact weights being served, see nn.Network.Fingerprint
+	Fingerprint string `json:"fingerprint"`
+}
+
+type predictRequest struct {
+	Input []float64 `json:"input"`
+}
+
+type predictResponse struct {
+	Output []float64 `json:"output"`
+}
+
+type batchRequest struct {
+	Inputs [][]float64 `json:"inputs"`
+}
+
+type batchResponse struct {
+	Outputs [][]float64 `json:"outputs"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server is an http.Handler serving predictions from a network. The network can be swapped with Update while
+// requests are being served.
+type Server struct {
+	model   nn.Publisher
+	mux     *http.ServeMux
+	metrics *metrics
+}
+
+// New Creates a Server for a network
+func New(n nn.Network) *Server {
+	s := &Server{mux: http.NewServeMux(), metrics: newMetrics()}
+	s.model.Publish(&n)
+
+	s.mux.HandleFunc("/predict", s.metrics.instrument(s.predict))
+	s.mux.HandleFunc("/predict/batch", s.metrics.instrument(s.batch))
+	s.mux.HandleFunc("/health", s.health)
+	s.mux.HandleFunc("/info", s.info)
+	s.mux.Handle("/metrics", s.metrics)
+
+	return s
+}
+
+// Load Creates a Server for a network saved with Network.Save
+func Load(filename string) (*Server, error) {
+	n, err := nn.Load(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(n), nil
+}
+
+// Update replaces the network being served. Requests already in progress finish using the old one.
+func (s *Server) Update(n nn.Network) {
+	s.model.Publish(&n)
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// predict evaluates a single input
+func (s *Server) predict(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req predictRequest
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxMessageSize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	snapshot := s.model.Load()
+
+	if err := checkInput(snapshot, req.Input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.metrics.predicted(1)
+	writeJSON(w, http.StatusOK, predictResponse{Output: snapshot.Calc(req.Input)})
+}
+
+// batch evaluates several inputs at once
+func (s *Server) batch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req batchRequest
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxMessageSize)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	snapshot := s.model.Load()
+	res := batchResponse{Outputs: make([][]float64, len(req.Inputs))}
+
+	for i, input := range req.Inputs {
+		if err := checkInput(snapshot, input); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("input %d: %s", i, err))
+			return
+		}
+
+		res.Outputs[i] = snapshot.Calc(input)
+	}
+
+	s.metrics.predicted(len(res.Outputs))
+	writeJSON(w, http.StatusOK, res)
+}
+
+// health reports that the server is up
+func (s *Server) health(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// info describes the model being served
+func (s *Server) info(w http.ResponseWriter, _ *http.Request) {
+	snapshot := s.model.Load()
+	n := snapshot.Network()
+	inputs, outputs := n.Dims()
+
+	writeJSON(w, http.StatusOK, Info{
+		Inputs:      inputs,
+		Outputs:     outputs,
+		Heads:       n.Heads(),
+		Fingerprint: snapshot.Fingerprint(),
+	})
+}
+
+// checkInput makes sure an input is the size the network expects
+func checkInput(snapshot *nn.Snapshot, input []float64) error {
+	inputs, _ := snapshot.Dims()
+
+	if len(input) != inputs {
+		return fmt.Errorf("input has %d values, network expects %d", len(input), inputs)
+	}
+
+	return nil
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorResponse{Error: msg})
+}
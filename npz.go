@@ -0,0 +1,87 @@
+package nn
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// SaveNPZ writes the weights of the network to a NumPy .npz archive, with one float64 array per parameter named as
+// in SaveSafetensors, so they can be loaded with numpy.load. Biases are saved as vectors.
+func (n Network) SaveNPZ(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	z := zip.NewWriter(f)
+	names, params := n.namedParams()
+
+	for i, p := range params {
+		var w io.Writer
+
+		w, err = z.Create(names[i] + ".npy")
+		if err != nil {
+			break
+		}
+
+		err = writeNPY(w, p, strings.HasSuffix(names[i], ".bias"))
+		if err != nil {
+			break
+		}
+	}
+
+	if err == nil {
+		err = z.Close()
+	}
+
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// writeNPY writes a matrix in the .npy format, as a vector if vector is set
+func writeNPY(w io.Writer, m mat.Matrix, vector bool) error {
+	r, c := m.Dims()
+
+	shape := fmt.Sprintf("(%d, %d)", r, c)
+	if vector {
+		shape = fmt.Sprintf("(%d,)", r*c)
+	}
+
+	header := fmt.Sprintf("{'descr': '<f8', 'fortran_order': False, 'shape': %s, }", shape)
+
+	// The magic string, version and header length take 10 bytes, and the header is padded so the data starts on a
+	// multiple of 64 bytes
+	pad := 64 - (10+len(header)+1)%64
+	if pad == 64 {
+		pad = 0
+	}
+
+	header += strings.Repeat(" ", pad) + "\n"
+
+	buf := make([]byte, 0, 10+len(header)+r*c*8)
+	buf = append(buf, "\x93NUMPY\x01\x00"...)
+	buf = append(buf, byte(len(header)), byte(len(header)>>8))
+	buf = append(buf, header...)
+
+	var v [8]byte
+
+	for y := 0; y < r; y++ {
+		for x := 0; x < c; x++ {
+			binary.LittleEndian.PutUint64(v[:], math.Float64bits(m.At(y, x)))
+			buf = append(buf, v[:]...)
+		}
+	}
+
+	_, err := w.Write(buf)
+	return err
+}
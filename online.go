@@ -0,0 +1,72 @@
+package nn
+
+// TrainOne performs a single step of gradient descent on one sample, for learning from data as it arrives. It returns
+// the cost of the sample before the update, which is an honest estimate of how well the network is doing on data it
+// hasn't seen yet.
+func (n *Network) TrainOne(input, expected []float64) (float64, error) {
+	return n.trainOne(SGD{}, input, expected)
+}
+
+// trainOne evaluates a sample and then steps opt with its gradients
+func (n *Network) trainOne(opt Optimizer, input, expected []float64) (float64, error) {
+	if err := n.checkSample(input, expected); err != nil {
+		return 0, err
+	}
+
+	loss := n.cost(n.Calc(input), expected)
+	opt.Step(n, n.Gradients(input, expected), n.learnRate)
+
+	return loss, nil
+}
+
+// Online trains a network one sample at a time with any optimizer, keeping a running average of the loss
+type Online struct {
+	// Smoothing is the weight given to the previous average when a new loss is added. Zero keeps a plain mean of
+	// every loss seen so far.
+	Smoothing float64
+
+	n    *Network
+	opt  Optimizer
+	loss float64
+	seen int
+}
+
+// NewOnline Creates a new online trainer for n. A nil optimizer uses SGD.
+func NewOnline(n *Network, opt Optimizer, smoothing float64) *Online {
+	if opt == nil {
+		opt = SGD{}
+	}
+
+	return &Online{Smoothing: smoothing, n: n, opt: opt}
+}
+
+// TrainOne trains the network on a single sample, returning its cost before the update
+func (o *Online) TrainOne(input, expected []float64) (float64, error) {
+	loss, err := o.n.trainOne(o.opt, input, expected)
+	if err != nil {
+		return 0, err
+	}
+
+	o.seen++
+
+	switch {
+	case o.seen == 1:
+		o.loss = loss
+	case o.Smoothing == 0:
+		o.loss += (loss - o.loss) / float64(o.seen)
+	default:
+		o.loss = o.Smoothing*o.loss + (1-o.Smoothing)*loss
+	}
+
+	return loss, nil
+}
+
+// Loss returns the running average of the loss
+func (o *Online) Loss() float64 {
+	return o.loss
+}
+
+// Seen returns the number of samples trained on
+func (o *Online) Seen() int {
+	return o.seen
+}
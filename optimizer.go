@@ -0,0 +1,321 @@
+package nn
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"io"
+	"math"
+)
+
+var (
+	errInvalidOptimizerState = errors.New("invalid optimizer state")
+	errNoOptimizerState      = errors.New("network has no optimizer state")
+)
+
+// optimizerAsset is the name optimizer state is stored under by SetOptimizerState
+const optimizerAsset = "optimizer.bin"
+
+// adaGradEpsilon keeps AdaGrad's steps finite for parameters which haven't had a gradient yet
+const adaGradEpsilon = 1e-8
+
+// Optimizer decides how gradients are turned into changes to a network's parameters. Optimizers with state, such as
+// Momentum, belong to a single network and should not be shared between training runs of different ones.
+type Optimizer interface {
+	// Step moves the parameters of n using one set of gradients and the current learning rate
+	Step(n *Network, grads Gradients, rate float64)
+}
+
+// Resetter is implemented by optimizers which keep state from one step to the next, such as a velocity
+type Resetter interface {
+	// Reset forgets the state, so the next step is taken as if it were the first
+	Reset()
+}
+
+// SGD is plain stochastic gradient descent, moving every parameter directly against its gradient. It is the
+// optimizer used when TrainOptions doesn't give one.
+type SGD struct{}
+
+// Step implements Optimizer
+func (SGD) Step(n *Network, grads Gradients, rate float64) {
+	n.ApplyGradients(grads, rate)
+}
+
+// Momentum is stochastic gradient descent with momentum, which keeps a decaying sum of past gradients and moves the
+// parameters along it. With Nesterov set the update looks ahead along the accumulated velocity first, which usually
+// converges faster at the same learning rate. The velocity can be saved with MarshalBinary to resume training later.
+type Momentum struct {
+	// Momentum is how much of the velocity is kept from one step to the next, usually around 0.9
+	Momentum float64
+
+	// Nesterov enables Nesterov accelerated gradient
+	Nesterov bool
+
+	velocity Gradients
+}
+
+// NewMomentum Creates a new momentum optimizer
+func NewMomentum(momentum float64, nesterov bool) *Momentum {
+	return &Momentum{Momentum: momentum, Nesterov: nesterov}
+}
+
+// Step implements Optimizer
+func (m *Momentum) Step(n *Network, grads Gradients, rate float64) {
+	if m.velocity == nil {
+		m.velocity = grads
+	} else {
+		m.velocity = m.velocity.Scale(m.Momentum).Add(grads)
+	}
+
+	if m.Nesterov {
+		n.ApplyGradients(grads.Add(m.velocity.Scale(m.Momentum)), rate)
+		return
+	}
+
+	n.ApplyGradients(m.velocity, rate)
+}
+
+// Reset implements Resetter, clearing the velocity
+func (m *Momentum) Reset() {
+	m.velocity = nil
+}
+
+// AdaGrad scales the step of every parameter by the inverse square root of the sum of its squared gradients, so rarely
+// updated parameters, such as the weights of sparse features, keep taking large steps. The accumulated squares can be
+// saved with MarshalBinary to resume training later.
+type AdaGrad struct {
+	squares [][]*mat.Dense
+}
+
+// NewAdaGrad Creates a new AdaGrad optimizer
+func NewAdaGrad() *AdaGrad {
+	return &AdaGrad{}
+}
+
+// Reset implements Resetter, clearing the accumulated squares
+func (a *AdaGrad) Reset() {
+	a.squares = nil
+}
+
+// Step implements Optimizer
+func (a *AdaGrad) Step(n *Network, grads Gradients, rate float64) {
+	if len(a.squares) != len(grads) {
+		a.squares = make([][]*mat.Dense, len(grads))
+	}
+
+	update := make(Gradients, len(grads))
+
+	for i := range grads {
+		if grads[i] == nil {
+			continue
+		}
+
+		if len(a.squares[i]) != len(grads[i]) {
+			a.squares[i] = make([]*mat.Dense, len(grads[i]))
+		}
+
+		update[i] = make([]mat.Matrix, len(grads[i]))
+
+		for j, g := range grads[i] {
+			if a.squares[i][j] == nil {
+				r, c := g.Dims()
+				a.squares[i][j] = mat.NewDense(r, c, nil)
+			}
+
+			sq := a.squares[i][j]
+			sq.Apply(func(y, x int, v float64) float64 {
+				return v + g.At(y, x)*g.At(y, x)
+			}, sq)
+
+			update[i][j] = fun(func(y, x int, v float64) float64 {
+				return v / (math.Sqrt(sq.At(y, x)) + adaGradEpsilon)
+			}, g)
+		}
+	}
+
+	n.ApplyGradients(update, rate)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, encoding the accumulated squared gradients
+func (a *AdaGrad) MarshalBinary() ([]byte, error) {
+	return marshalMatrices(a.squares)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, restoring state written by MarshalBinary
+func (a *AdaGrad) UnmarshalBinary(data []byte) error {
+	squares, err := unmarshalMatrices(data)
+	if err != nil {
+		return err
+	}
+
+	a.squares = squares
+	return nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler, encoding the velocity
+func (m *Momentum) MarshalBinary() ([]byte, error) {
+	velocity := make([][]*mat.Dense, len(m.velocity))
+
+	for i, layer := range m.velocity {
+		for _, v := range layer {
+			velocity[i] = append(velocity[i], mat.DenseCopyOf(v))
+		}
+	}
+
+	return marshalMatrices(velocity)
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, restoring state written by MarshalBinary
+func (m *Momentum) UnmarshalBinary(data []byte) error {
+	velocity, err := unmarshalMatrices(data)
+	if err != nil {
+		return err
+	}
+
+	m.velocity = make(Gradients, len(velocity))
+
+	for i, layer := range velocity {
+		for _, v := range layer {
+			if v == nil {
+				return errInvalidOptimizerState
+			}
+
+			m.velocity[i] = append(m.velocity[i], v)
+		}
+	}
+
+	if len(m.velocity) == 0 {
+		m.velocity = nil
+	}
+
+	return nil
+}
+
+// SetOptimizerState stores the state of an optimizer as an asset of the network, so that it is saved along with it
+// and training can later resume with the same state instead of starting cold. Optimizers without state, like SGD,
+// remove any state stored before.
+func (n *Network) SetOptimizerState(opt Optimizer) error {
+	m, ok := opt.(encoding.BinaryMarshaler)
+	if !ok {
+		return n.SetAsset(optimizerAsset, nil)
+	}
+
+	state, err := m.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%T", opt)
+
+	return n.SetAsset(optimizerAsset, append([]byte(name+"\n"), state...))
+}
+
+// RestoreOptimizerState loads the state stored by SetOptimizerState into opt, which must be of the same type as the
+// optimizer it was taken from
+func (n Network) RestoreOptimizerState(opt Optimizer) error {
+	data, ok := n.Asset(optimizerAsset)
+	if !ok {
+		return errNoOptimizerState
+	}
+
+	u, ok := opt.(encoding.BinaryUnmarshaler)
+	name := fmt.Sprintf("%T", opt)
+
+	if !ok || !bytes.HasPrefix(data, []byte(name+"\n")) {
+		return fmt.Errorf("%w for %s", errInvalidOptimizerState, name)
+	}
+
+	return u.UnmarshalBinary(data[len(name)+1:])
+}
+
+// marshalMatrices encodes the per layer state of an optimizer, which may have gaps
+func marshalMatrices(layers [][]*mat.Dense) ([]byte, error) {
+	var buf bytes.Buffer
+
+	writeUint := func(v int) {
+		_ = binary.Write(&buf, binary.LittleEndian, uint64(v))
+	}
+
+	writeUint(len(layers))
+
+	for _, layer := range layers {
+		writeUint(len(layer))
+
+		for _, m := range layer {
+			if m == nil {
+				writeUint(0)
+				continue
+			}
+
+			data, err := m.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+
+			writeUint(len(data))
+			buf.Write(data)
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// unmarshalMatrices decodes state written by marshalMatrices
+func unmarshalMatrices(data []byte) ([][]*mat.Dense, error) {
+	r := bytes.NewReader(data)
+
+	readUint := func() (int, error) {
+		var v uint64
+
+		err := binary.Read(r, binary.LittleEndian, &v)
+		if err != nil || v > uint64(r.Len()) {
+			return 0, errInvalidOptimizerState
+		}
+
+		return int(v), nil
+	}
+
+	count, err := readUint()
+	if err != nil {
+		return nil, err
+	}
+
+	layers := make([][]*mat.Dense, count)
+
+	for i := range layers {
+		count, err := readUint()
+		if err != nil {
+			return nil, err
+		}
+
+		layers[i] = make([]*mat.Dense, count)
+
+		for j := range layers[i] {
+			size, err := readUint()
+			if err != nil {
+				return nil, err
+			}
+
+			if size == 0 {
+				continue
+			}
+
+			blob := make([]byte, size)
+
+			if _, err = io.ReadFull(r, blob); err != nil {
+				return nil, errInvalidOptimizerState
+			}
+
+			layers[i][j] = &mat.Dense{}
+
+			if err = layers[i][j].UnmarshalBinary(blob); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return layers, nil
+}
@@ -0,0 +1,81 @@
+package nn
+
+import (
+	"gonum.org/v1/gonum/mat"
+)
+
+// Params returns every parameter of the network in a single vector, layer by layer in the order of Gradients,
+// with each matrix stored row by row. Together with SetParams it lets optimizers and analysis tools work on the
+// network as a point in weight space.
+func (n Network) Params() []float64 {
+	var res []float64
+
+	for _, l := range n.layers {
+		for _, p := range l.params() {
+			r, c := p.Dims()
+
+			for y := 0; y < r; y++ {
+				for x := 0; x < c; x++ {
+					res = append(res, p.At(y, x))
+				}
+			}
+		}
+	}
+
+	return res
+}
+
+// SetParams replaces every parameter of the network with values laid out as by Params, returning
+// ErrDimensionMismatch if there are too few or too many of them. The values are copied.
+func (n *Network) SetParams(values []float64) error {
+	total := 0
+
+	for _, l := range n.layers {
+		for _, p := range l.params() {
+			r, c := p.Dims()
+			total += r * c
+		}
+	}
+
+	if len(values) != total {
+		return ErrDimensionMismatch
+	}
+
+	for i := range n.layers {
+		params := n.layers[i].params()
+
+		for j, p := range params {
+			r, c := p.Dims()
+			params[j] = mat.NewDense(r, c, append([]float64(nil), values[:r*c]...))
+			values = values[r*c:]
+		}
+
+		n.layers[i].setParams(params)
+	}
+
+	return nil
+}
+
+// flatGradients lays out gradients for the network in a single vector matching Params, with zeros for layers that
+// have no gradients
+func (n Network) flatGradients(grads Gradients) []float64 {
+	var res []float64
+
+	for i, l := range n.layers {
+		for j, p := range l.params() {
+			r, c := p.Dims()
+
+			for y := 0; y < r; y++ {
+				for x := 0; x < c; x++ {
+					if grads[i] == nil {
+						res = append(res, 0)
+					} else {
+						res = append(res, grads[i][j].At(y, x))
+					}
+				}
+			}
+		}
+	}
+
+	return res
+}
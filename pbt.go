@@ -0,0 +1,172 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+var (
+	errInvalidPBT = errors.New("population based training needs a population of at least 2 and positive rounds")
+)
+
+// PBTMember is a member of a population trained by PBT
+type PBTMember struct {
+	Network Network
+	Params  map[string]float64
+
+	// Loss is the loss returned by the last round of training
+	Loss float64
+}
+
+// PBTTrain trains a member of the population for a number of epochs with the given hyperparameters, and returns its
+// loss, such as the validation cost. Lower losses are better. It is called for every member at the same time, so it
+// must be safe for concurrent use.
+type PBTTrain func(n *Network, params map[string]float64, epochs int) (float64, error)
+
+// PBT is population based training. A population of networks with different hyperparameters is trained in parallel,
+// and after every round the worst members are replaced by copies of the best, whose hyperparameters are then
+// perturbed. The hyperparameters are tuned while the networks train, and the schedule the best network followed is
+// often better than any fixed choice.
+type PBT struct {
+	// Params are the hyperparameters, which start out random
+	Params []Param
+
+	// Population is the number of networks trained
+	Population int
+
+	// Rounds is the number of rounds, and Epochs the number of epochs each member is trained for in every round
+	Rounds, Epochs int
+
+	// Fraction is the fraction of the population replaced after every round, and also the fraction copied from, 0.25
+	// when zero
+	Fraction float64
+
+	// Factor is how much a copied hyperparameter is perturbed, multiplying it by 1+Factor or 1-Factor, 0.2 when zero.
+	// Perturbed values are kept within their Param's range.
+	Factor float64
+
+	// WeightNoise, when it isn't zero, also perturbs the weights of copied networks by up to that much with Perturb
+	WeightNoise float64
+}
+
+// Run trains a population of networks made by newNetwork and returns the members after the last round, best first.
+// Members replaced between rounds are given clones of better ones, so custom layers must be registered with
+// RegisterLayer.
+func (p PBT) Run(newNetwork func(params map[string]float64) Network, train PBTTrain) ([]PBTMember, error) {
+	if p.Population < 2 || p.Rounds < 1 || p.Epochs < 1 {
+		return nil, errInvalidPBT
+	}
+
+	for _, param := range p.Params {
+		if param.Min >= param.Max || param.Log && param.Min <= 0 {
+			return nil, fmt.Errorf("%w: %s", errInvalidSearch, param.Name)
+		}
+	}
+
+	fraction, factor := p.Fraction, p.Factor
+
+	if fraction == 0 {
+		fraction = 0.25
+	}
+
+	if factor == 0 {
+		factor = 0.2
+	}
+
+	members := make([]PBTMember, p.Population)
+
+	for i := range members {
+		members[i].Params = make(map[string]float64, len(p.Params))
+
+		for _, param := range p.Params {
+			members[i].Params[param.Name] = param.value(rand.Float64())
+		}
+
+		members[i].Network = newNetwork(members[i].Params)
+	}
+
+	replaced := int(fraction * float64(p.Population))
+
+	if replaced < 1 {
+		replaced = 1
+	}
+
+	if replaced > p.Population/2 {
+		replaced = p.Population / 2
+	}
+
+	for round := 0; round < p.Rounds; round++ {
+		jobs := make([]func() error, len(members))
+
+		for i := range members {
+			m := &members[i]
+
+			jobs[i] = func() (err error) {
+				m.Loss, err = train(&m.Network, m.Params, p.Epochs)
+				return err
+			}
+		}
+
+		if err := parallel(jobs); err != nil {
+			return nil, fmt.Errorf("round %d: %w", round+1, err)
+		}
+
+		sort.SliceStable(members, func(a, b int) bool {
+			return members[a].Loss < members[b].Loss
+		})
+
+		if round == p.Rounds-1 {
+			break
+		}
+
+		// The worst members exploit the best by copying them, then explore by perturbing the copy
+		for i := len(members) - replaced; i < len(members); i++ {
+			src := members[rand.Intn(replaced)]
+
+			n, err := src.Network.Clone()
+			if err != nil {
+				return nil, err
+			}
+
+			members[i].Network = n
+			members[i].Params = p.perturb(src.Params, factor)
+
+			if p.WeightNoise != 0 {
+				members[i].Network.Perturb(p.WeightNoise)
+			}
+		}
+	}
+
+	return members, nil
+}
+
+// perturb returns a copy of params with every hyperparameter multiplied by 1+factor or 1-factor, moving integers by
+// at least one
+func (p PBT) perturb(params map[string]float64, factor float64) map[string]float64 {
+	res := make(map[string]float64, len(params))
+
+	for _, param := range p.Params {
+		old, dir := params[param.Name], 1.0
+
+		if rand.Intn(2) == 0 {
+			dir = -1
+		}
+
+		v := old * (1 + dir*factor)
+
+		if param.Integer {
+			v = math.Round(v)
+
+			if v == old {
+				v += dir
+			}
+		}
+
+		res[param.Name] = math.Min(param.Max, math.Max(param.Min, v))
+	}
+
+	return res
+}
@@ -0,0 +1,132 @@
+package nn
+
+import (
+	"errors"
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+	"gonum.org/v1/plot/vg/draw"
+	"image/color"
+	"math"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	errUnknownPlotFormat = errors.New("unknown plot format")
+	errEmptyHistory      = errors.New("no epochs to plot")
+)
+
+// Dimensions of a plotted learning curve
+const (
+	plotWidth  = 8 * vg.Inch
+	plotHeight = 5 * vg.Inch
+)
+
+// series is a single line of a learning curve
+type series struct {
+	name   string
+	colour color.RGBA
+	values []float64
+}
+
+// PlotHistory draws the training loss, and the validation loss if there was validation data, of the epochs in a
+// Report, with labelled axes and a legend. The format is chosen by the extension of filename, which can be .svg or
+// .png. Lines are broken at epochs with a loss of NaN or infinity.
+func PlotHistory(history []Epoch, filename string) error {
+	if len(history) == 0 {
+		return errEmptyHistory
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".svg", ".png":
+	default:
+		return errUnknownPlotFormat
+	}
+
+	p := plot.New()
+	p.X.Label.Text = "epoch"
+	p.Y.Label.Text = "loss"
+	p.Legend.Top = true
+	p.Add(plotter.NewGrid())
+
+	for _, s := range historySeries(history) {
+		for j, points := range s.segments() {
+			line, err := plotter.NewLine(points)
+			if err != nil {
+				return err
+			}
+
+			line.Color = s.colour
+			line.Width = vg.Points(2)
+			p.Add(line)
+
+			// A single epoch between gaps has no line to draw, so it is marked with a dot instead
+			if len(points) == 1 {
+				dot, err := plotter.NewScatter(points)
+				if err != nil {
+					return err
+				}
+
+				dot.Color = s.colour
+				dot.Shape = draw.CircleGlyph{}
+				p.Add(dot)
+			}
+
+			if j == 0 {
+				p.Legend.Add(s.name, line)
+			}
+		}
+	}
+
+	return p.Save(plotWidth, plotHeight, filename)
+}
+
+// segments splits a series into the runs of epochs between NaN and infinite losses, which can't be drawn
+func (s series) segments() []plotter.XYs {
+	var (
+		res     []plotter.XYs
+		current plotter.XYs
+	)
+
+	for i, v := range s.values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			if len(current) > 0 {
+				res = append(res, current)
+			}
+
+			current = nil
+			continue
+		}
+
+		current = append(current, plotter.XY{X: float64(i + 1), Y: v})
+	}
+
+	if len(current) > 0 {
+		res = append(res, current)
+	}
+
+	return res
+}
+
+// historySeries returns the lines of a learning curve, leaving out the validation loss if there was no validation data
+func historySeries(history []Epoch) []series {
+	train := series{name: "training", colour: color.RGBA{R: 31, G: 119, B: 180, A: 255}}
+	val := series{name: "validation", colour: color.RGBA{R: 255, G: 127, B: 14, A: 255}}
+	hasVal := false
+
+	for _, e := range history {
+		train.values = append(train.values, e.Loss)
+		val.values = append(val.values, e.ValLoss)
+
+		if e.HasValidation {
+			hasVal = true
+		}
+	}
+
+	if !hasVal {
+		return []series{train}
+	}
+
+	return []series{train, val}
+}
@@ -0,0 +1,132 @@
+package nn
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Prediction is a single output of the network along with its score
+type Prediction struct {
+	Index int
+	Score float64
+}
+
+// TopK evaluates the data and returns the k outputs with the highest scores, best first. If k is larger than the
+// number of outputs every output is returned.
+func (n Network) TopK(data []float64, k int) []Prediction {
+	res := n.Calc(data)
+	predictions := make([]Prediction, len(res))
+
+	for i, score := range res {
+		predictions[i] = Prediction{Index: i, Score: score}
+	}
+
+	sort.SliceStable(predictions, func(i, j int) bool {
+		return predictions[i].Score > predictions[j].Score
+	})
+
+	if k < 0 {
+		k = 0
+	}
+
+	if k < len(predictions) {
+		predictions = predictions[:k]
+	}
+
+	return predictions
+}
+
+// PredictCSVOptions configures PredictCSV
+type PredictCSVOptions struct {
+	// Echo writes each row's inputs before its outputs
+	Echo bool
+
+	// Header writes a first row naming the columns
+	Header bool
+
+	// Workers is the number of goroutines evaluating the inputs, 1 when zero
+	Workers int
+}
+
+// PredictCSV evaluates every input of a dataset and writes the outputs to w as CSV, one row per sample in the same
+// order as the dataset. The inputs are evaluated with Stream, so the rows are written as they become available.
+func (n Network) PredictCSV(w io.Writer, d Dataset, opts PredictCSVOptions) error {
+	cw := csv.NewWriter(w)
+
+	if opts.Header {
+		var record []string
+
+		if opts.Echo {
+			for i := 0; i < n.i; i++ {
+				record = append(record, "input_"+strconv.Itoa(i))
+			}
+		}
+
+		for i := 0; i < n.o; i++ {
+			record = append(record, "output_"+strconv.Itoa(i))
+		}
+
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	in := make(chan []float64)
+
+	go func() {
+		defer close(in)
+
+		for i := 0; i < d.Len(); i++ {
+			input, _ := d.Sample(i)
+			in <- input
+		}
+	}()
+
+	var (
+		err error
+		row = 0
+	)
+
+	// Every result is read even after an error so the goroutines feeding and running the stream can finish
+	for r := range n.Stream(in, StreamOptions{Workers: opts.Workers, BatchSize: 64, Window: 10 * time.Millisecond}) {
+		row++
+
+		if err != nil {
+			continue
+		}
+
+		if r.Err != nil {
+			err = fmt.Errorf("sample %d: %w", row-1, r.Err)
+			continue
+		}
+
+		var record []string
+
+		if opts.Echo {
+			record = appendFloats(record, r.Input)
+		}
+
+		err = cw.Write(appendFloats(record, r.Output))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
+
+// appendFloats formats values for CSV
+func appendFloats(record []string, values []float64) []string {
+	for _, v := range values {
+		record = append(record, strconv.FormatFloat(v, 'g', -1, 64))
+	}
+
+	return record
+}
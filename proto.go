@@ -0,0 +1,286 @@
+package nn
+
+import (
+	"errors"
+	"github.com/e74000/nn/internal/nnpb"
+	"gonum.org/v1/gonum/mat"
+	"google.golang.org/protobuf/proto"
+)
+
+//go:generate protoc --go_out=. --go_opt=module=github.com/e74000/nn network.proto
+
+var (
+	errInvalidProto = errors.New("invalid network message")
+)
+
+// MarshalProto encodes the network as a protocol buffers Network message, described in network.proto. Fields added
+// to the schema later are ignored by older readers, and missing fields take their zero values.
+func (n Network) MarshalProto() ([]byte, error) {
+	msg := &nnpb.Network{LearnRate: n.learnRate}
+
+	for _, size := range n.inputs {
+		msg.Inputs = append(msg.Inputs, int32(size))
+	}
+
+	for _, l := range n.layers {
+		pl := &nnpb.Layer{
+			From:       int32(l.from),
+			Size:       int32(l.size()),
+			Activation: l.activation.Name,
+			Alpha:      l.activation.Alpha,
+			Dropout:    l.dropout,
+		}
+
+		if l.custom != nil {
+			data, err := l.custom.MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+
+			pl.Custom, pl.CustomData = l.custom.Name(), data
+		} else {
+			if l.tie == nil {
+				pl.Weights = marshalMatrix(l.weights)
+			} else {
+				pl.Tie = &nnpb.Tie{Source: int32(l.tie.source), Transpose: l.tie.transpose}
+			}
+
+			if l.biases != nil {
+				pl.Biases = marshalMatrix(l.biases)
+			} else {
+				pl.NoBias = true
+			}
+		}
+
+		if l.slope != nil {
+			pl.Slope = l.slope.At(0, 0)
+		}
+
+		for _, s := range l.skips {
+			ps := &nnpb.Skip{From: int32(s.from)}
+
+			if s.weights != nil {
+				ps.Weights = marshalMatrix(s.weights)
+			}
+
+			pl.Skips = append(pl.Skips, ps)
+		}
+
+		msg.Layers = append(msg.Layers, pl)
+	}
+
+	for i, a := range n.outputs {
+		msg.Outputs = append(msg.Outputs, &nnpb.Output{
+			Activation: int32(a),
+			Loss:       n.heads[i].loss.Name,
+			Weight:     n.heads[i].weight,
+		})
+	}
+
+	if t := n.Temperature(); t != 1 {
+		msg.Temperature = t
+	}
+
+	for _, name := range n.Assets() {
+		msg.Assets = append(msg.Assets, &nnpb.Asset{Name: name, Data: n.assets[name]})
+	}
+
+	return proto.Marshal(msg)
+}
+
+// marshalMatrix encodes a Matrix message
+func marshalMatrix(m mat.Matrix) *nnpb.Matrix {
+	r, c := m.Dims()
+
+	return &nnpb.Matrix{Rows: int32(r), Cols: int32(c), Data: mat.DenseCopyOf(m).RawMatrix().Data}
+}
+
+// UnmarshalProto decodes a network encoded by MarshalProto. Custom layers must be registered with RegisterLayer.
+func UnmarshalProto(data []byte) (Network, error) {
+	n, err := unmarshalProto(data)
+	return n, corrupt(err)
+}
+
+// unmarshalProto decodes a network encoded by MarshalProto
+func unmarshalProto(data []byte) (Network, error) {
+	var msg nnpb.Network
+
+	err := proto.Unmarshal(data, &msg)
+	if err != nil {
+		return Network{}, err
+	}
+
+	g := NewGraph()
+
+	for _, size := range msg.Inputs {
+		g.Input(int(size))
+	}
+
+	for _, l := range msg.Layers {
+		if l.Custom == "" && l.NoBias {
+			g.DenseNoBias(int(l.Size), int(l.From))
+			continue
+		}
+
+		if l.Custom == "" {
+			g.Dense(int(l.Size), int(l.From))
+			continue
+		}
+
+		c, err := newRegisteredLayer(l.Custom)
+		if err != nil {
+			return Network{}, err
+		}
+
+		err = c.UnmarshalBinary(l.CustomData)
+		if err != nil {
+			return Network{}, err
+		}
+
+		g.Layer(c, int(l.From))
+	}
+
+	outputs := make([]int, len(msg.Outputs))
+
+	for i, o := range msg.Outputs {
+		outputs[i] = int(o.Activation)
+	}
+
+	n, err := g.Network(outputs, msg.LearnRate, false)
+	if err != nil {
+		return Network{}, err
+	}
+
+	for i, l := range msg.Layers {
+		layer := &n.layers[i]
+
+		if l.Activation != "" {
+			a := Activation{Name: l.Activation, Alpha: l.Alpha}
+
+			if !a.valid() {
+				return Network{}, errUnknownActivation
+			}
+
+			layer.setActivation(a)
+
+			if a == PReLU {
+				layer.slope = mat.NewDense(1, 1, []float64{l.Slope})
+			}
+		}
+
+		if l.Custom == "" {
+			weights, err := unmarshalMatrix(l.Weights)
+			if err != nil {
+				return Network{}, err
+			}
+
+			biases, err := unmarshalMatrix(l.Biases)
+			if err != nil {
+				return Network{}, err
+			}
+
+			if (biases == nil) != (layer.biases == nil) || (biases != nil && !sameDims(biases, layer.biases)) {
+				return Network{}, errInvalidProto
+			}
+
+			switch {
+			case l.Tie != nil:
+				err = n.TieWeights(i, int(l.Tie.Source), l.Tie.Transpose)
+				if err != nil {
+					return Network{}, err
+				}
+			case weights == nil || !sameDims(weights, layer.weights):
+				return Network{}, errInvalidProto
+			default:
+				layer.weights = weights
+			}
+
+			if biases != nil {
+				layer.biases = biases
+			}
+		}
+
+		err = n.SetDropout(i, l.Dropout)
+		if err != nil {
+			return Network{}, err
+		}
+
+		for _, s := range l.Skips {
+			weights, err := unmarshalMatrix(s.Weights)
+			if err != nil {
+				return Network{}, err
+			}
+
+			err = n.AddSkip(int(s.From), len(n.inputs)+i, weights != nil)
+			if err != nil {
+				return Network{}, err
+			}
+
+			if weights == nil {
+				continue
+			}
+
+			sk := &layer.skips[len(layer.skips)-1]
+
+			if !sameDims(weights, sk.weights) {
+				return Network{}, errInvalidProto
+			}
+
+			sk.weights = weights
+		}
+	}
+
+	for i, o := range msg.Outputs {
+		h := head{loss: Loss{Name: o.Loss}, weight: o.Weight}
+
+		if h.loss.Name == "" {
+			h.loss = defaultHead.loss
+		}
+
+		if !h.loss.valid() {
+			return Network{}, errUnknownLoss
+		}
+
+		n.heads[i] = h
+	}
+
+	if msg.Temperature != 0 {
+		err = n.SetTemperature(msg.Temperature)
+		if err != nil {
+			return Network{}, err
+		}
+	}
+
+	for _, a := range msg.Assets {
+		// The data is kept non-nil when it is empty, as SetAsset removes assets set to nil
+		err = n.SetAsset(a.Name, append([]byte{}, a.Data...))
+		if err != nil {
+			return Network{}, err
+		}
+	}
+
+	return n, nil
+}
+
+// unmarshalMatrix decodes a Matrix message, returning nil if it is missing
+func unmarshalMatrix(m *nnpb.Matrix) (*mat.Dense, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	r, c := int(m.Rows), int(m.Cols)
+
+	if r <= 0 || c <= 0 || len(m.Data) != r*c {
+		return nil, errInvalidProto
+	}
+
+	return mat.NewDense(r, c, m.Data), nil
+}
+
+// sameDims checks that two matrices are the same size
+func sameDims(a, b mat.Matrix) bool {
+	ar, ac := a.Dims()
+	br, bc := b.Dims()
+
+	return ar == br && ac == bc
+}
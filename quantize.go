@@ -0,0 +1,64 @@
+package nn
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+// fakeQuantize rounds every value of m to the nearest of the 255 evenly spaced levels between -max|m| and max|m|
+// that symmetric int8 quantization stores, returning them as floats
+func fakeQuantize(m mat.Matrix) mat.Matrix {
+	limit := math.Max(mat.Max(m), -mat.Min(m))
+
+	if limit == 0 {
+		return m
+	}
+
+	scale := limit / 127
+
+	return fun(func(_, _ int, v float64) float64 {
+		return math.Round(v/scale) * scale
+	}, m)
+}
+
+// fakeQuantizeActivations rounds every value of m to the nearest of the 256 evenly spaced levels between its smallest
+// and largest values that asymmetric uint8 quantization stores, returning them as floats
+func fakeQuantizeActivations(m mat.Matrix) mat.Matrix {
+	lo, hi := mat.Min(m), mat.Max(m)
+
+	if hi <= lo {
+		return m
+	}
+
+	scale := (hi - lo) / 255
+
+	return fun(func(_, _ int, v float64) float64 {
+		return lo + math.Round((v-lo)/scale)*scale
+	}, m)
+}
+
+// Quantized returns a copy of the network as it would behave quantized to int8, with the weights of its dense layers
+// rounded to 8 bits and the activations of every layer rounded as they are calculated. Comparing its cost with the
+// network's shows how much accuracy quantization costs, which training with TrainOptions.QuantizationAware reduces.
+func (n *Network) Quantized() Network {
+	m := n.Copy()
+	m.quantize = true
+
+	for i, l := range m.layers {
+		if l.custom != nil {
+			continue
+		}
+
+		if l.weights != nil {
+			m.layers[i].weights = fakeQuantize(l.weights)
+		}
+
+		for j, s := range l.skips {
+			if s.weights != nil {
+				m.layers[i].skips[j].weights = fakeQuantize(s.weights)
+			}
+		}
+	}
+
+	return m
+}
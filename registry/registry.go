@@ -0,0 +1,228 @@
+// Package registry keeps versioned networks in a directory. Every version is saved under a tag along with when it was
+// saved, its fingerprint and any metadata given, and can be loaded by tag or as "latest".
+//
+// The directory holds one subdirectory per tag:
+//
+//	models/
+//	  v1/model.zip
+//	  v1/version.json
+//	  v2/model.zip
+//	  v2/version.json
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/e74000/nn"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Latest is the tag which loads the most recently saved version
+const Latest = "latest"
+
+const (
+	modelFile   = "model.zip"
+	versionFile = "version.json"
+)
+
+var (
+	errInvalidTag = errors.New("invalid version tag")
+	errNotFound   = errors.New("version not found")
+	errExists     = errors.New("version already exists")
+)
+
+// Version describes a saved network
+type Version struct {
+	Tag         string            `json:"tag"`
+	Saved       time.Time         `json:"saved"`
+	Fingerprint string            `json:"fingerprint"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// Registry is a directory of versioned networks
+type Registry struct {
+	dir string
+}
+
+// Open Opens the registry in dir, creating the directory if it doesn't exist
+func Open(dir string) (*Registry, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Registry{dir: dir}, nil
+}
+
+// Save saves a network under tag, which must not already be used. An empty tag picks the next of v1, v2 and so on.
+func (r *Registry) Save(n nn.Network, tag string, metadata map[string]string) (Version, error) {
+	if tag == "" {
+		next, err := r.nextTag()
+		if err != nil {
+			return Version{}, err
+		}
+
+		tag = next
+	}
+
+	if !validTag(tag) {
+		return Version{}, errInvalidTag
+	}
+
+	if _, err := os.Stat(filepath.Join(r.dir, tag)); err == nil {
+		return Version{}, errExists
+	}
+
+	v := Version{
+		Tag:         tag,
+		Saved:       time.Now().UTC(),
+		Fingerprint: n.Fingerprint(),
+		Metadata:    metadata,
+	}
+
+	// The version is written to a temporary directory first so a partly saved version is never listed
+	tmp, err := ioutil.TempDir(r.dir, ".save-")
+	if err != nil {
+		return Version{}, err
+	}
+
+	defer os.RemoveAll(tmp)
+
+	err = n.Save(filepath.Join(tmp, modelFile))
+	if err != nil {
+		return Version{}, err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return Version{}, err
+	}
+
+	err = ioutil.WriteFile(filepath.Join(tmp, versionFile), data, 0o644)
+	if err != nil {
+		return Version{}, err
+	}
+
+	err = os.Rename(tmp, filepath.Join(r.dir, tag))
+	if err != nil {
+		return Version{}, err
+	}
+
+	return v, nil
+}
+
+// List returns every saved version, oldest first
+func (r *Registry) List() ([]Version, error) {
+	entries, err := ioutil.ReadDir(r.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []Version
+
+	for _, e := range entries {
+		if !e.IsDir() || !validTag(e.Name()) {
+			continue
+		}
+
+		v, err := r.version(e.Name())
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, v)
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		return versions[i].Saved.Before(versions[j].Saved)
+	})
+
+	return versions, nil
+}
+
+// Load loads the network saved under tag, or the most recently saved one for Latest
+func (r *Registry) Load(tag string) (nn.Network, Version, error) {
+	if tag == Latest {
+		versions, err := r.List()
+		if err != nil {
+			return nn.Network{}, Version{}, err
+		}
+
+		if len(versions) == 0 {
+			return nn.Network{}, Version{}, errNotFound
+		}
+
+		tag = versions[len(versions)-1].Tag
+	}
+
+	if !validTag(tag) {
+		return nn.Network{}, Version{}, errInvalidTag
+	}
+
+	v, err := r.version(tag)
+	if err != nil {
+		return nn.Network{}, Version{}, err
+	}
+
+	n, err := nn.Load(filepath.Join(r.dir, tag, modelFile))
+	if err != nil {
+		return nn.Network{}, Version{}, err
+	}
+
+	return n, v, nil
+}
+
+// version reads the description of a saved version
+func (r *Registry) version(tag string) (Version, error) {
+	data, err := ioutil.ReadFile(filepath.Join(r.dir, tag, versionFile))
+	if os.IsNotExist(err) {
+		return Version{}, errNotFound
+	}
+
+	if err != nil {
+		return Version{}, err
+	}
+
+	var v Version
+
+	err = json.Unmarshal(data, &v)
+	if err != nil {
+		return Version{}, err
+	}
+
+	return v, nil
+}
+
+// nextTag finds the first unused tag of the form v1, v2 and so on
+func (r *Registry) nextTag() (string, error) {
+	versions, err := r.List()
+	if err != nil {
+		return "", err
+	}
+
+	used := make(map[string]bool, len(versions))
+
+	for _, v := range versions {
+		used[v.Tag] = true
+	}
+
+	for i := len(versions) + 1; ; i++ {
+		tag := fmt.Sprintf("v%d", i)
+
+		if !used[tag] {
+			return tag, nil
+		}
+	}
+}
+
+// validTag checks that a tag can be used as a directory name and isn't reserved
+func validTag(tag string) bool {
+	return tag != "" && tag != Latest && tag != "." && tag != ".." && !strings.HasPrefix(tag, ".") &&
+		!strings.ContainsAny(tag, `/\:`)
+}
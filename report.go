@@ -0,0 +1,91 @@
+package nn
+
+import (
+	"time"
+)
+
+// Hyperparameters describes the settings a network was trained with
+type Hyperparameters struct {
+	Inputs    int     `json:"inputs"`
+	Outputs   int     `json:"outputs"`
+	Layers    []int   `json:"layers"`
+	LearnRate float64 `json:"learn_rate"`
+	Epochs    int     `json:"epochs"`
+
+	WeightDecay float64 `json:"weight_decay,omitempty"`
+	NoBiasDecay bool    `json:"no_bias_decay,omitempty"`
+
+	GradientNoise float64 `json:"gradient_noise,omitempty"`
+
+	AdversarialEpsilon float64 `json:"adversarial_epsilon,omitempty"`
+
+	InputMasking float64 `json:"input_masking,omitempty"`
+	InputNoise   float64 `json:"input_noise,omitempty"`
+}
+
+// Report describes a training run, and can be marshaled to JSON for experiment tracking
+type Report struct {
+	Hyperparameters Hyperparameters `json:"hyperparameters"`
+
+	// TrainSamples and ValSamples are the sizes of the training and validation sets
+	TrainSamples int `json:"train_samples"`
+	ValSamples   int `json:"val_samples"`
+
+	// Epochs holds the details of every completed epoch
+	Epochs []Epoch `json:"epochs"`
+
+	// Duration is the time taken by the whole run
+	Duration time.Duration `json:"duration_ns"`
+
+	// FinalLoss and FinalValLoss are the average costs of the trained network over the training and validation sets
+	FinalLoss    float64 `json:"final_loss"`
+	FinalValLoss float64 `json:"final_val_loss,omitempty"`
+
+	// Error is set when a callback stopped training early
+	Error string `json:"error,omitempty"`
+}
+
+// newReport starts a report for a training run
+func (n Network) newReport(inputs [][]float64, opts TrainOptions) Report {
+	layers := make([]int, n.h)
+
+	for i, l := range n.layers {
+		layers[i] = l.size()
+	}
+
+	return Report{
+		Hyperparameters: Hyperparameters{
+			Inputs:    n.i,
+			Outputs:   n.o,
+			Layers:    layers,
+			LearnRate: n.learnRate,
+			Epochs:    opts.Epochs,
+
+			WeightDecay: opts.WeightDecay,
+			NoBiasDecay: opts.NoBiasDecay,
+
+			GradientNoise: opts.GradientNoise,
+
+			AdversarialEpsilon: opts.AdversarialEpsilon,
+
+			InputMasking: opts.InputMasking,
+			InputNoise:   opts.InputNoise,
+		},
+		TrainSamples: len(inputs),
+		ValSamples:   len(opts.ValInputs),
+		Epochs:       make([]Epoch, 0, opts.Epochs),
+	}
+}
+
+// finish evaluates the trained network and records how long the run took
+func (r *Report) finish(n *Network, inputs, expected [][]float64, opts TrainOptions, start time.Time) {
+	if len(inputs) > 0 {
+		r.FinalLoss = n.Cost(inputs, expected)
+	}
+
+	if len(opts.ValInputs) > 0 {
+		r.FinalValLoss = n.Cost(opts.ValInputs, opts.ValExpected)
+	}
+
+	r.Duration = time.Since(start)
+}
@@ -0,0 +1,205 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"math/rand"
+)
+
+var (
+	errInvalidAction = errors.New("invalid action")
+	errEmptyEpisode  = errors.New("no steps to learn from")
+)
+
+// Step is a single state, the action taken in it and the reward received for it
+type Step struct {
+	State  []float64
+	Action int
+	Reward float64
+}
+
+// Episode is the trajectory of a single run of an environment
+type Episode []Step
+
+// Add records a step of the episode
+func (e *Episode) Add(state []float64, action int, reward float64) {
+	*e = append(*e, Step{State: state, Action: action, Reward: reward})
+}
+
+// Returns calculates the discounted return from every step of the episode to its end
+func (e Episode) Returns(gamma float64) []float64 {
+	res := make([]float64, len(e))
+	g := 0.0
+
+	for t := len(e) - 1; t >= 0; t-- {
+		g = e[t].Reward + gamma*g
+		res[t] = g
+	}
+
+	return res
+}
+
+// ActionProbs treats the outputs of the network as the scores of a policy, returning the probability of taking each
+// action in a state. A linear output activation usually works best for policies, and a network with a single Softmax
+// output already gives the probabilities, so they are returned as they are.
+func (n Network) ActionProbs(state []float64) []float64 {
+	if n.softmaxOutput() {
+		return n.Calc(state)
+	}
+
+	return softmax(n.Calc(state))
+}
+
+// softmaxOutput reports whether the network has a single output, which is a dense layer with a Softmax activation
+func (n Network) softmaxOutput() bool {
+	if len(n.outputs) != 1 || n.outputs[0] < len(n.inputs) {
+		return false
+	}
+
+	l := n.layers[n.outputs[0]-len(n.inputs)]
+
+	return l.custom == nil && l.activation == Softmax
+}
+
+// SampleAction picks an action for a state at random according to ActionProbs
+func (n Network) SampleAction(state []float64) int {
+	probs := n.ActionProbs(state)
+	r := rand.Float64()
+
+	for a, p := range probs {
+		r -= p
+		if r < 0 {
+			return a
+		}
+	}
+
+	return len(probs) - 1
+}
+
+// Reinforce trains a policy network with the REINFORCE policy gradient algorithm, making actions that led to high
+// returns more likely
+type Reinforce struct {
+	// Gamma is the discount applied to future rewards
+	Gamma float64
+
+	// Normalize scales the returns of every update to zero mean and unit variance, which reduces the variance of the
+	// gradients a lot
+	Normalize bool
+
+	// Optimizer applies the gradients, SGD when nil
+	Optimizer Optimizer
+}
+
+// Update performs a single policy gradient step using the given episodes
+func (r Reinforce) Update(n *Network, episodes ...Episode) error {
+	var (
+		steps   []Step
+		returns []float64
+	)
+
+	for _, e := range episodes {
+		steps = append(steps, e...)
+		returns = append(returns, e.Returns(r.Gamma)...)
+	}
+
+	if len(steps) == 0 {
+		return errEmptyEpisode
+	}
+
+	if r.Normalize {
+		normalize(returns)
+	}
+
+	var total Gradients
+
+	for t, s := range steps {
+		if err := n.checkInput(s.State); err != nil {
+			return fmt.Errorf("step %d: %w", t, err)
+		}
+
+		if s.Action < 0 || s.Action >= n.o {
+			return errInvalidAction
+		}
+
+		grads := n.policyGradients(s, returns[t])
+
+		if total == nil {
+			total = grads
+			continue
+		}
+
+		total = total.Add(grads)
+	}
+
+	opt := r.Optimizer
+	if opt == nil {
+		opt = SGD{}
+	}
+
+	opt.Step(n, total.Scale(1/float64(len(steps))), n.learnRate)
+	return nil
+}
+
+// policyGradients returns the gradients of -g * log π(action | state), where π is given by ActionProbs
+func (n Network) policyGradients(s Step, g float64) Gradients {
+	zs, activations, masks := n.forwardDropout(split(s.State, n.inputs), true)
+
+	var scores []float64
+
+	for _, a := range n.outputs {
+		scores = append(scores, mat.Col(nil, 0, activations[a])...)
+	}
+
+	var probs []float64
+
+	if n.softmaxOutput() {
+		// The outputs are π itself, and the softmax's own gradient turns this into g(π - 1) for the action
+		probs = make([]float64, len(scores))
+		probs[s.Action] = -g / math.Max(scores[s.Action], crossEntropyClamp)
+	} else {
+		probs = softmax(scores)
+
+		for a := range probs {
+			probs[a] *= g
+		}
+
+		probs[s.Action] -= g
+	}
+
+	outputGrads := make([]mat.Matrix, len(n.outputs))
+	offset := 0
+
+	for i, size := range n.outputSizes() {
+		outputGrads[i] = mat.NewDense(size, 1, probs[offset:offset+size])
+		offset += size
+	}
+
+	grads, _ := n.backward(zs, activations, masks, outputGrads)
+
+	return grads
+}
+
+// normalize scales values in place to zero mean and unit variance
+func normalize(values []float64) {
+	mean := 0.0
+
+	for _, v := range values {
+		mean += v
+	}
+
+	mean /= float64(len(values))
+
+	variance := 0.0
+
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+
+	std := math.Sqrt(variance / float64(len(values)))
+
+	for i := range values {
+		values[i] = (values[i] - mean) / math.Max(std, 1e-8)
+	}
+}
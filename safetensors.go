@@ -0,0 +1,305 @@
+package nn
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"strings"
+)
+
+var (
+	errInvalidSafetensors = fmt.Errorf("%w: invalid safetensors file", ErrCorruptModel)
+	errMissingTensor      = errors.New("missing tensor")
+)
+
+// safetensorsMaxHeader limits the size of header LoadSafetensors will read
+const safetensorsMaxHeader = 100 << 20
+
+// safetensor is the header entry of a single tensor
+type safetensor struct {
+	Dtype   string   `json:"dtype"`
+	Shape   []int    `json:"shape"`
+	Offsets [2]int64 `json:"data_offsets"`
+}
+
+// namedParams returns every parameter of the network along with a name for it. Dense layers have
+// "layers.i.weight" unless their weights are tied, "layers.i.bias", "layers.i.skips.j.weight" for their projection
+// skips and "layers.i.prelu" for a PReLU slope, and custom layers have "layers.i.params.j".
+func (n Network) namedParams() ([]string, []mat.Matrix) {
+	var (
+		names  []string
+		params []mat.Matrix
+	)
+
+	for i, l := range n.layers {
+		ps := l.params()
+
+		if l.custom != nil {
+			for j, p := range ps {
+				names = append(names, fmt.Sprintf("layers.%d.params.%d", i, j))
+				params = append(params, p)
+			}
+
+			continue
+		}
+
+		if l.tie == nil {
+			names = append(names, fmt.Sprintf("layers.%d.weight", i))
+			params, ps = append(params, ps[0]), ps[1:]
+		}
+
+		if l.biases != nil {
+			names = append(names, fmt.Sprintf("layers.%d.bias", i))
+			params, ps = append(params, ps[0]), ps[1:]
+		}
+
+		for j, s := range l.skips {
+			if s.weights == nil {
+				continue
+			}
+
+			names = append(names, fmt.Sprintf("layers.%d.skips.%d.weight", i, j))
+			params, ps = append(params, ps[0]), ps[1:]
+		}
+
+		if l.slope != nil {
+			names = append(names, fmt.Sprintf("layers.%d.prelu", i))
+			params = append(params, l.slope)
+		}
+	}
+
+	return names, params
+}
+
+// SaveSafetensors writes the weights of the network to a safetensors file as 64 bit floats. Only the weights are
+// saved, so loading them needs a network with the same architecture.
+func (n Network) SaveSafetensors(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+
+	err = n.WriteSafetensors(w)
+	if err == nil {
+		err = w.Flush()
+	}
+
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// WriteSafetensors writes the weights of the network in the safetensors format
+func (n Network) WriteSafetensors(w io.Writer) error {
+	names, params := n.namedParams()
+
+	header := map[string]interface{}{
+		"__metadata__": map[string]string{"format": "nn", "fingerprint": n.Fingerprint()},
+	}
+
+	offset := int64(0)
+
+	for i, p := range params {
+		r, c := p.Dims()
+		size := int64(r*c) * 8
+
+		shape := []int{r, c}
+
+		// Biases are vectors, as they are in most other libraries
+		if strings.HasSuffix(names[i], ".bias") {
+			shape = []int{r}
+		}
+
+		header[names[i]] = safetensor{Dtype: "F64", Shape: shape, Offsets: [2]int64{offset, offset + size}}
+		offset += size
+	}
+
+	data, err := json.Marshal(header)
+	if err != nil {
+		return err
+	}
+
+	// The header is padded with spaces so the tensors are aligned
+	for len(data)%8 != 0 {
+		data = append(data, ' ')
+	}
+
+	err = binary.Write(w, binary.LittleEndian, uint64(len(data)))
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(data)
+	if err != nil {
+		return err
+	}
+
+	var buf [8]byte
+
+	for _, p := range params {
+		r, c := p.Dims()
+
+		for y := 0; y < r; y++ {
+			for x := 0; x < c; x++ {
+				binary.LittleEndian.PutUint64(buf[:], math.Float64bits(p.At(y, x)))
+
+				if _, err = w.Write(buf[:]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// LoadSafetensors replaces the weights of the network with those in a safetensors file, using the names written by
+// SaveSafetensors. Tensors may be 32 or 64 bit floats, and must have the shapes the network expects, though vectors
+// are accepted for any column vector. Extra tensors are ignored.
+func (n *Network) LoadSafetensors(filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+
+	return n.loadSafetensors(data, false)
+}
+
+// loadSafetensors replaces the weights of the network with those in a safetensors file. When share is true, aligned
+// 64 bit tensors use data directly instead of being copied.
+func (n *Network) loadSafetensors(data []byte, share bool) error {
+	tensors, body, err := parseSafetensors(data)
+	if err != nil {
+		return err
+	}
+
+	names, params := n.namedParams()
+	loaded := make([]*mat.Dense, len(params))
+
+	for i, name := range names {
+		t, ok := tensors[name]
+		if !ok {
+			return fmt.Errorf("%w: %s", errMissingTensor, name)
+		}
+
+		r, c := params[i].Dims()
+
+		if len(t.Shape) == 1 && c == 1 {
+			t.Shape = []int{t.Shape[0], 1}
+		}
+
+		if len(t.Shape) != 2 || t.Shape[0] != r || t.Shape[1] != c {
+			return fmt.Errorf("%w: %s has shape %v, expected [%d %d]", ErrIncompatibleArchitectures, name, t.Shape, r, c)
+		}
+
+		loaded[i], err = t.matrix(body, r, c, share)
+		if err != nil {
+			return err
+		}
+	}
+
+	k := 0
+
+	for i := range n.layers {
+		ps := n.layers[i].params()
+
+		for j := range ps {
+			ps[j] = loaded[k]
+			k++
+		}
+
+		n.layers[i].setParams(ps)
+	}
+
+	return nil
+}
+
+// parseSafetensors splits a safetensors file into its tensor headers and the data they point into
+func parseSafetensors(data []byte) (map[string]safetensor, []byte, error) {
+	if len(data) < 8 {
+		return nil, nil, errInvalidSafetensors
+	}
+
+	size := binary.LittleEndian.Uint64(data)
+
+	if size > safetensorsMaxHeader || size > uint64(len(data)-8) {
+		return nil, nil, errInvalidSafetensors
+	}
+
+	var raw map[string]json.RawMessage
+
+	if err := json.Unmarshal(data[8:8+size], &raw); err != nil {
+		return nil, nil, errInvalidSafetensors
+	}
+
+	tensors := make(map[string]safetensor, len(raw))
+
+	for k, v := range raw {
+		if k == "__metadata__" {
+			continue
+		}
+
+		var t safetensor
+
+		if err := json.Unmarshal(v, &t); err != nil {
+			return nil, nil, errInvalidSafetensors
+		}
+
+		tensors[k] = t
+	}
+
+	return tensors, data[8+size:], nil
+}
+
+// matrix reads the tensor's values from the data section of a file. When share is true, 64 bit tensors that can be
+// used in place are backed by body rather than copied.
+func (t safetensor) matrix(body []byte, r, c int, share bool) (*mat.Dense, error) {
+	width := int64(0)
+
+	switch t.Dtype {
+	case "F64":
+		width = 8
+	case "F32":
+		width = 4
+	default:
+		return nil, fmt.Errorf("%w: unsupported dtype %s", errInvalidSafetensors, t.Dtype)
+	}
+
+	start, end := t.Offsets[0], t.Offsets[1]
+
+	if start < 0 || end > int64(len(body)) || end-start != int64(r*c)*width {
+		return nil, errInvalidSafetensors
+	}
+
+	raw := body[start:end]
+
+	if share && width == 8 {
+		if values, ok := float64s(raw); ok {
+			return mat.NewDense(r, c, values), nil
+		}
+	}
+
+	values := make([]float64, r*c)
+
+	for i := range values {
+		if width == 8 {
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(raw[i*8:]))
+		} else {
+			values[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(raw[i*4:])))
+		}
+	}
+
+	return mat.NewDense(r, c, values), nil
+}
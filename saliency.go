@@ -0,0 +1,55 @@
+package nn
+
+import (
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+)
+
+// InputGradient returns the gradient of a single output of the network with respect to each of the inputs for the
+// given data. Large values mark the inputs the output is most sensitive to, which is useful for saliency maps.
+func (n Network) InputGradient(data []float64, output int) []float64 {
+	if err := n.checkInput(data); err != nil {
+		panic(err)
+	}
+
+	if output < 0 || output >= n.o {
+		panic(fmt.Errorf("%w: output %d of a network with %d outputs", ErrDimensionMismatch, output, n.o))
+	}
+
+	zs, activations := n.forward(split(data, n.inputs))
+	outputGrads := make([]mat.Matrix, len(n.outputs))
+
+	for i, size := range n.outputSizes() {
+		if output < size {
+			seed := mat.NewDense(size, 1, nil)
+			seed.Set(output, 0, 1)
+			outputGrads[i] = seed
+			break
+		}
+
+		output -= size
+	}
+
+	_, layerErrors := n.backward(zs, activations, nil, outputGrads)
+
+	return n.inputValues(layerErrors)
+}
+
+// inputValues concatenates the gradients of every input returned by backward, using zero for inputs that don't lead
+// to an output
+func (n Network) inputValues(layerErrors []mat.Matrix) []float64 {
+	res := make([]float64, 0, n.i)
+
+	for a, size := range n.inputs {
+		for j := 0; j < size; j++ {
+			if layerErrors[a] == nil {
+				res = append(res, 0)
+				continue
+			}
+
+			res = append(res, layerErrors[a].At(j, 0))
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,192 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+var (
+	errInvalidOrder = errors.New("invalid sample order")
+)
+
+// Sampler chooses the samples TrainWith trains on during an epoch. Order returns the indices of the samples to use,
+// in the order they should be presented. An index may appear more than once, or not at all.
+type Sampler interface {
+	Order(epoch int, inputs, expected [][]float64) []int
+}
+
+// sampleOrder returns the order of the samples for an epoch, checking any order given by the sampler
+func sampleOrder(s Sampler, epoch int, inputs, expected [][]float64) ([]int, error) {
+	if s == nil {
+		order := make([]int, len(inputs))
+
+		for i := range order {
+			order[i] = i
+		}
+
+		return order, nil
+	}
+
+	order := s.Order(epoch, inputs, expected)
+
+	for _, i := range order {
+		if i < 0 || i >= len(inputs) {
+			return nil, fmt.Errorf("%w: index %d in epoch %d, with %d samples", errInvalidOrder, i, epoch, len(inputs))
+		}
+	}
+
+	return order, nil
+}
+
+// Curriculum is a Sampler which presents the easiest samples first and phases in the harder ones over a number of
+// epochs. Samples are sorted by Difficulty, lowest first, and every epoch trains on the easiest part of them. That
+// part starts as the fraction Start of the samples and grows evenly until every sample is used after Epochs epochs.
+type Curriculum struct {
+	// Difficulty scores a sample, lower scores being easier
+	Difficulty func(input, expected []float64) float64
+
+	// Epochs is the number of epochs it takes to phase in every sample
+	Epochs int
+
+	// Start is the fraction of the samples used in the first epoch
+	Start float64
+
+	sorted []int
+}
+
+// NewCurriculum Creates a new curriculum
+func NewCurriculum(difficulty func(input, expected []float64) float64, epochs int, start float64) *Curriculum {
+	return &Curriculum{
+		Difficulty: difficulty,
+		Epochs:     epochs,
+		Start:      start,
+	}
+}
+
+// Order implements Sampler. The difficulty of the samples is only scored once, on the first call.
+func (c *Curriculum) Order(epoch int, inputs, expected [][]float64) []int {
+	if len(c.sorted) != len(inputs) {
+		scores := make([]float64, len(inputs))
+		c.sorted = make([]int, len(inputs))
+
+		for i := range inputs {
+			scores[i] = c.Difficulty(inputs[i], expected[i])
+			c.sorted[i] = i
+		}
+
+		sort.SliceStable(c.sorted, func(a, b int) bool {
+			return scores[c.sorted[a]] < scores[c.sorted[b]]
+		})
+	}
+
+	frac := 1.0
+
+	if c.Epochs > 0 && epoch <= c.Epochs {
+		frac = c.Start + (1-c.Start)*float64(epoch-1)/float64(c.Epochs)
+	}
+
+	count := int(math.Ceil(frac * float64(len(inputs))))
+
+	if count < 1 {
+		count = 1
+	}
+
+	if count > len(inputs) {
+		count = len(inputs)
+	}
+
+	return c.sorted[:count]
+}
+
+// LossRecorder can be implemented by a Sampler to be told the loss of every sample after each epoch. RecordLosses is
+// given the loss of each sample after it was trained on, which is NaN for samples that weren't used in the epoch.
+type LossRecorder interface {
+	RecordLosses(epoch int, losses []float64)
+}
+
+// HardExampleMining is a Sampler which oversamples the examples the network is worst at. Every epoch uses every
+// sample once, and after the first epoch the fraction Fraction of the samples with the highest loss in the previous
+// epoch are repeated Repeats more times, shuffled in with the rest.
+type HardExampleMining struct {
+	// Fraction is the part of the samples that are oversampled
+	Fraction float64
+
+	// Repeats is the number of extra times each hard example is used per epoch
+	Repeats int
+
+	losses []float64
+}
+
+// NewHardExampleMining Creates a new hard example mining sampler
+func NewHardExampleMining(fraction float64, repeats int) *HardExampleMining {
+	return &HardExampleMining{
+		Fraction: fraction,
+		Repeats:  repeats,
+	}
+}
+
+// Order implements Sampler
+func (h *HardExampleMining) Order(epoch int, inputs, expected [][]float64) []int {
+	order := make([]int, len(inputs))
+
+	for i := range order {
+		order[i] = i
+	}
+
+	if len(h.losses) != len(inputs) {
+		return order
+	}
+
+	for _, i := range h.Hardest() {
+		for r := 0; r < h.Repeats; r++ {
+			order = append(order, i)
+		}
+	}
+
+	rand.Shuffle(len(order), func(a, b int) {
+		order[a], order[b] = order[b], order[a]
+	})
+
+	return order
+}
+
+// RecordLosses implements LossRecorder. Samples missing a loss keep the one they had before.
+func (h *HardExampleMining) RecordLosses(epoch int, losses []float64) {
+	if len(h.losses) != len(losses) {
+		h.losses = make([]float64, len(losses))
+	}
+
+	for i, loss := range losses {
+		if !math.IsNaN(loss) {
+			h.losses[i] = loss
+		}
+	}
+}
+
+// Hardest returns the indices of the hard examples found in the last epoch, with the highest loss first
+func (h *HardExampleMining) Hardest() []int {
+	sorted := make([]int, len(h.losses))
+
+	for i := range sorted {
+		sorted[i] = i
+	}
+
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return h.losses[sorted[a]] > h.losses[sorted[b]]
+	})
+
+	count := int(math.Ceil(h.Fraction * float64(len(sorted))))
+
+	if count > len(sorted) {
+		count = len(sorted)
+	}
+
+	if count < 0 {
+		count = 0
+	}
+
+	return sorted[:count]
+}
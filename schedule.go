@@ -0,0 +1,101 @@
+package nn
+
+import (
+	"math"
+)
+
+// ReduceLROnPlateau returns a callback which multiplies the learning rate by factor whenever the validation cost
+// hasn't improved for patience epochs, but never below floor. The training cost is watched instead when there is no
+// validation set. Each epoch of the report records the learning rate it was trained with.
+func ReduceLROnPlateau(factor float64, patience int, floor float64) Callback {
+	var (
+		best  = math.Inf(1)
+		stale = 0
+	)
+
+	return func(n *Network, e Epoch) error {
+		cost := e.Loss
+
+		if e.HasValidation {
+			cost = e.ValLoss
+		}
+
+		if cost < best {
+			best, stale = cost, 0
+			return nil
+		}
+
+		stale++
+
+		if stale >= patience && n.learnRate > floor {
+			n.learnRate = math.Max(n.learnRate*factor, floor)
+			stale = 0
+		}
+
+		return nil
+	}
+}
+
+// WarmRestarts anneals the learning rate along a cosine from MaxRate down to MinRate over every cycle of epochs, then
+// restarts it at MaxRate (SGDR). Restarting repeatedly knocks training out of plateaus, and the best weights are often
+// found at the end of a cycle. Pass its Callback to TrainWith.
+type WarmRestarts struct {
+	// MaxRate is the learning rate at the start of every cycle, the network's learning rate when training starts when
+	// it is zero
+	MaxRate float64
+
+	// MinRate is the learning rate the end of every cycle approaches
+	MinRate float64
+
+	// Period is the number of epochs in the first cycle, and every later cycle is Multiplier times longer than the one
+	// before it, or the same length when Multiplier is zero
+	Period     int
+	Multiplier int
+
+	// Optimizer, when it is a Resetter, has its state reset at every restart, so that stale moments don't carry the
+	// parameters past where the restart leads them
+	Optimizer Optimizer
+
+	start, length int
+}
+
+// NewWarmRestarts Creates a schedule whose first cycle lasts period epochs, with every later cycle multiplier times
+// longer, annealing towards minRate
+func NewWarmRestarts(period, multiplier int, minRate float64) *WarmRestarts {
+	return &WarmRestarts{MinRate: minRate, Period: period, Multiplier: multiplier}
+}
+
+// Callback sets the learning rate for the next epoch
+func (w *WarmRestarts) Callback(n *Network, e Epoch) error {
+	if w.length == 0 {
+		w.length = w.Period
+
+		if w.length < 1 {
+			w.length = 1
+		}
+
+		if w.MaxRate == 0 {
+			w.MaxRate = e.LearnRate
+		}
+	}
+
+	// t is the number of epochs of the current cycle that have been completed
+	t := e.Epoch - w.start
+
+	if t >= w.length {
+		w.start = e.Epoch
+		t = 0
+
+		if w.Multiplier > 1 {
+			w.length *= w.Multiplier
+		}
+
+		if r, ok := w.Optimizer.(Resetter); ok {
+			r.Reset()
+		}
+	}
+
+	n.learnRate = w.MinRate + (w.MaxRate-w.MinRate)*(1+math.Cos(math.Pi*float64(t)/float64(w.length)))/2
+
+	return nil
+}
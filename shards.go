@@ -0,0 +1,201 @@
+package nn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+var (
+	errInvalidShardSize = errors.New("shard size must be positive")
+)
+
+// shardsFormat identifies manifests written by SaveSharded
+const shardsFormat = "nn-shards"
+
+// ShardManifest lists the files a sharded network was split into, in order
+type ShardManifest struct {
+	Format string  `json:"format"`
+	Size   int64   `json:"size"`
+	Shards []Shard `json:"shards"`
+}
+
+// Shard is a single piece of a sharded network. Path is relative to the manifest.
+type Shard struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// SaveSharded saves the network like Save, but split into files of at most shardSize bytes, for models too large for
+// a single file or object. filename is a JSON manifest listing the shards, which are written next to it as
+// filename.00000, filename.00001 and so on. Load reassembles the network from the manifest.
+func (n Network) SaveSharded(filename string, shardSize int64) error {
+	if shardSize <= 0 {
+		return errInvalidShardSize
+	}
+
+	w := &shardWriter{base: filename, limit: shardSize, manifest: ShardManifest{Format: shardsFormat}}
+
+	err := n.writeZip(w)
+	if err == nil {
+		err = w.finish()
+	}
+
+	if err != nil {
+		if w.f != nil {
+			_ = w.f.Close()
+		}
+
+		return err
+	}
+
+	data, err := json.MarshalIndent(w.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filename, data, 0o644)
+}
+
+// shardWriter writes to a sequence of files, starting a new one whenever the current one reaches limit bytes
+type shardWriter struct {
+	base     string
+	limit    int64
+	manifest ShardManifest
+
+	f       *os.File
+	written int64
+	hash    hash.Hash
+}
+
+// Write implements io.Writer
+func (w *shardWriter) Write(p []byte) (int, error) {
+	total := 0
+
+	for len(p) > 0 {
+		if w.f == nil || w.written == w.limit {
+			if err := w.next(); err != nil {
+				return total, err
+			}
+		}
+
+		chunk := p
+
+		if int64(len(chunk)) > w.limit-w.written {
+			chunk = chunk[:w.limit-w.written]
+		}
+
+		c, err := w.f.Write(chunk)
+		w.hash.Write(chunk[:c])
+		w.written += int64(c)
+		w.manifest.Size += int64(c)
+		total += c
+
+		if err != nil {
+			return total, err
+		}
+
+		p = p[c:]
+	}
+
+	return total, nil
+}
+
+// next closes the current shard, if there is one, and starts the next
+func (w *shardWriter) next() error {
+	if err := w.finish(); err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("%s.%05d", w.base, len(w.manifest.Shards))
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	w.f, w.written, w.hash = f, 0, sha256.New()
+	w.manifest.Shards = append(w.manifest.Shards, Shard{Path: filepath.Base(path)})
+
+	return nil
+}
+
+// finish closes the current shard and records its size and hash
+func (w *shardWriter) finish() error {
+	if w.f == nil {
+		return nil
+	}
+
+	s := &w.manifest.Shards[len(w.manifest.Shards)-1]
+	s.Size, s.SHA256 = w.written, hex.EncodeToString(w.hash.Sum(nil))
+
+	err := w.f.Close()
+	w.f = nil
+
+	return err
+}
+
+// parseShardManifest reads a manifest written by SaveSharded, reporting whether data is one
+func parseShardManifest(data []byte) (ShardManifest, bool) {
+	var m ShardManifest
+
+	if err := json.Unmarshal(data, &m); err != nil || m.Format != shardsFormat {
+		return ShardManifest{}, false
+	}
+
+	return m, true
+}
+
+// join reads the shards listed by the manifest from dir and joins them back together, checking each against the size
+// and hash the manifest records
+func (m ShardManifest) join(dir string) ([]byte, error) {
+	// The sizes come from the manifest, so they are checked against the files and each other before the buffer is
+	// allocated
+	total := int64(0)
+
+	for i, s := range m.Shards {
+		info, err := os.Stat(filepath.Join(dir, filepath.Base(s.Path)))
+		if err != nil {
+			return nil, err
+		}
+
+		if info.Size() != s.Size || s.Size > m.Size-total {
+			return nil, fmt.Errorf("%w: shard %d doesn't match its manifest", ErrCorruptModel, i)
+		}
+
+		total += s.Size
+	}
+
+	if m.Size < 0 || total != m.Size {
+		return nil, fmt.Errorf("%w: shards hold %d bytes, manifest expects %d", ErrCorruptModel, total, m.Size)
+	}
+
+	var buf bytes.Buffer
+
+	buf.Grow(int(m.Size))
+
+	for i, s := range m.Shards {
+		data, err := ioutil.ReadFile(filepath.Join(dir, filepath.Base(s.Path)))
+		if err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(data)
+
+		if int64(len(data)) != s.Size || hex.EncodeToString(sum[:]) != s.SHA256 {
+			return nil, fmt.Errorf("%w: shard %d doesn't match its manifest", ErrCorruptModel, i)
+		}
+
+		buf.Write(data)
+	}
+
+	return buf.Bytes(), nil
+}
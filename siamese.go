@@ -0,0 +1,177 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	errNoPairs         = errors.New("no pairs to learn from")
+	errUnknownDistance = errors.New("unknown distance")
+)
+
+// Distance measures how far apart two embeddings are
+type Distance int
+
+const (
+	// Euclidean is the euclidean distance between embeddings
+	Euclidean Distance = iota
+
+	// Cosine is one minus the cosine similarity of embeddings, between 0 for embeddings pointing the same way and 2
+	// for opposite ones. It is 1 when either embedding is zero.
+	Cosine
+)
+
+// valid reports whether d is a known distance
+func (d Distance) valid() bool {
+	return d == Euclidean || d == Cosine
+}
+
+// between returns the distance between a and b along with its gradients with respect to each of them
+func (d Distance) between(a, b []float64) (dist float64, ga, gb []float64) {
+	ga, gb = make([]float64, len(a)), make([]float64, len(b))
+
+	if d == Cosine {
+		ab, na, nb := 0.0, 0.0, 0.0
+
+		for i := range a {
+			ab += a[i] * b[i]
+			na += a[i] * a[i]
+			nb += b[i] * b[i]
+		}
+
+		if na == 0 || nb == 0 {
+			return 1, ga, gb
+		}
+
+		na, nb = math.Sqrt(na), math.Sqrt(nb)
+		sim := ab / (na * nb)
+
+		for i := range a {
+			ga[i] = -(b[i]/(na*nb) - sim*a[i]/(na*na))
+			gb[i] = -(a[i]/(na*nb) - sim*b[i]/(nb*nb))
+		}
+
+		return 1 - sim, ga, gb
+	}
+
+	dist = math.Sqrt(squaredDistance(a, b))
+
+	if dist == 0 {
+		return 0, ga, gb
+	}
+
+	for i := range a {
+		ga[i] = (a[i] - b[i]) / dist
+		gb[i] = -ga[i]
+	}
+
+	return dist, ga, gb
+}
+
+// Compare runs two inputs through the network and returns the distance between their outputs
+func (n Network) Compare(a, b []float64, d Distance) float64 {
+	if !d.valid() {
+		panic(errUnknownDistance)
+	}
+
+	dist, _, _ := d.between(n.Calc(a), n.Calc(b))
+	return dist
+}
+
+// Pair is a training example for a siamese network: two inputs and whether they are similar
+type Pair struct {
+	A, B    []float64
+	Similar bool
+}
+
+// ContrastiveLoss returns the contrastive loss of a pair whose embeddings are dist apart: dist² for similar pairs,
+// and max(0, margin - dist)² for dissimilar ones, which stop contributing once they are margin apart
+func ContrastiveLoss(dist float64, similar bool, margin float64) float64 {
+	if similar {
+		return dist * dist
+	}
+
+	return math.Pow(math.Max(0, margin-dist), 2)
+}
+
+// ContrastiveTrainer trains a siamese network, which embeds both inputs of a pair with the same network, by
+// minimising the ContrastiveLoss of every pair
+type ContrastiveTrainer struct {
+	// Margin is the distance dissimilar pairs are pushed apart to
+	Margin float64
+
+	// Distance is the distance between embeddings, Euclidean by default
+	Distance Distance
+
+	// Optimizer applies the gradients of every pair, SGD when nil
+	Optimizer Optimizer
+}
+
+// Train trains the network on every pair in order for a number of epochs, returning the average loss of each epoch
+func (c ContrastiveTrainer) Train(n *Network, pairs []Pair, epochs int) ([]float64, error) {
+	if len(pairs) == 0 {
+		return nil, errNoPairs
+	}
+
+	if !c.Distance.valid() {
+		return nil, errUnknownDistance
+	}
+
+	for i, p := range pairs {
+		for _, input := range [][]float64{p.A, p.B} {
+			if err := n.checkInput(input); err != nil {
+				return nil, fmt.Errorf("pair %d: %w", i, err)
+			}
+		}
+	}
+
+	opt := c.Optimizer
+	if opt == nil {
+		opt = SGD{}
+	}
+
+	losses := make([]float64, epochs)
+
+	for epoch := range losses {
+		for _, p := range pairs {
+			grads, loss := n.contrastiveGradients(p, c.Distance, c.Margin)
+			losses[epoch] += loss
+
+			if grads != nil {
+				opt.Step(n, grads, n.learnRate)
+			}
+		}
+
+		losses[epoch] /= float64(len(pairs))
+	}
+
+	return losses, nil
+}
+
+// contrastiveGradients returns the gradients of the ContrastiveLoss of a pair and the loss itself. The gradients are
+// nil when the loss is zero.
+func (n Network) contrastiveGradients(p Pair, d Distance, margin float64) (Gradients, float64) {
+	ea, eb := n.embed(p.A), n.embed(p.B)
+	dist, ga, gb := d.between(ea.values, eb.values)
+
+	loss := ContrastiveLoss(dist, p.Similar, margin)
+	if loss == 0 {
+		return nil, 0
+	}
+
+	// scale is the derivative of the loss with respect to the distance
+	scale := 2 * dist
+
+	if !p.Similar {
+		scale = -2 * (margin - dist)
+	}
+
+	for i := range ga {
+		ga[i] *= scale
+		gb[i] *= scale
+	}
+
+	return n.embeddingGradients(ea, ga).Add(n.embeddingGradients(eb, gb)), loss
+}
@@ -0,0 +1,89 @@
+package nn
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/mat"
+)
+
+var (
+	errInvalidSkip = errors.New("invalid skip connection")
+)
+
+// SkipOptions is for exporting skip connection information to JSON
+type SkipOptions struct {
+	From, To int
+	Path     string
+}
+
+// skip feeds an earlier activation straight into the weighted input of a later layer
+type skip struct {
+	from    int
+	weights mat.Matrix // nil for an identity connection
+}
+
+// forward calculates the skip's contribution to the weighted input of the layer it feeds
+func (s skip) forward(activation mat.Matrix) mat.Matrix {
+	if s.weights == nil {
+		return activation
+	}
+
+	return dot(s.weights, activation)
+}
+
+// backward carries a layer's error back along the skip to the activation it came from
+func (s skip) backward(delta mat.Matrix) mat.Matrix {
+	if s.weights == nil {
+		return delta
+	}
+
+	return dot(s.weights.T(), delta)
+}
+
+// size returns the size of a given activation
+func (n Network) size(activation int) int {
+	if activation < len(n.inputs) {
+		return n.inputs[activation]
+	}
+
+	return n.layers[activation-len(n.inputs)].size()
+}
+
+// AddSkip connects activation from directly to the layer producing activation to, which must come after it. If
+// project is false the connection is an identity and both activations must be the same size, otherwise a trainable
+// projection is used which starts at zero so the network's output is unchanged.
+func (n *Network) AddSkip(from, to int, project bool) error {
+	if from < 0 || to < len(n.inputs) || to >= len(n.inputs)+n.h || from >= to {
+		return errInvalidSkip
+	}
+
+	l := &n.layers[to-len(n.inputs)]
+
+	if l.custom != nil {
+		return errInvalidSkip
+	}
+
+	if !project {
+		if n.size(from) != n.size(to) {
+			return errInvalidSkip
+		}
+
+		l.skips = append(l.skips, skip{from: from})
+		return nil
+	}
+
+	l.skips = append(l.skips, skip{
+		from:    from,
+		weights: mat.NewDense(n.size(to), n.size(from), nil),
+	})
+
+	return nil
+}
+
+// accumulate adds m onto acc, treating a nil acc as zero
+func accumulate(acc, m mat.Matrix) mat.Matrix {
+	if acc == nil {
+		return m
+	}
+
+	return add(acc, m)
+}
@@ -0,0 +1,62 @@
+package nn
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Snapshot is a frozen copy of a network for inference. Training the network it was taken from doesn't affect it,
+// and it is safe to use from any number of goroutines. The parameters of user defined layers are still shared with
+// the original network.
+type Snapshot struct {
+	n Network
+
+	fingerprint     string
+	fingerprintOnce sync.Once
+}
+
+// Snapshot takes a frozen copy of the network's current weights
+func (n *Network) Snapshot() *Snapshot {
+	return &Snapshot{n: n.Copy()}
+}
+
+// Calc evaluates a given input using the snapshot's weights
+func (s *Snapshot) Calc(data []float64) []float64 {
+	return s.n.Calc(data)
+}
+
+// Dims returns the total size of the snapshot's inputs and outputs
+func (s *Snapshot) Dims() (inputs, outputs int) {
+	return s.n.Dims()
+}
+
+// Fingerprint returns the Fingerprint of the snapshot's network, which is only calculated once
+func (s *Snapshot) Fingerprint() string {
+	s.fingerprintOnce.Do(func() {
+		s.fingerprint = s.n.Fingerprint()
+	})
+
+	return s.fingerprint
+}
+
+// Network returns a copy of the network the snapshot holds, for use with the network's other read-only methods
+func (s *Snapshot) Network() Network {
+	return s.n.Copy()
+}
+
+// Publisher hands out the most recently published snapshot of a network. A training loop can publish new weights
+// while other goroutines keep evaluating whatever was published last, without any locking.
+type Publisher struct {
+	v atomic.Value
+}
+
+// Publish takes a snapshot of the network and makes it the one returned by Load
+func (p *Publisher) Publish(n *Network) {
+	p.v.Store(n.Snapshot())
+}
+
+// Load returns the most recently published snapshot, or nil if nothing has been published yet
+func (p *Publisher) Load() *Snapshot {
+	s, _ := p.v.Load().(*Snapshot)
+	return s
+}
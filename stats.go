@@ -0,0 +1,132 @@
+package nn
+
+import (
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+// Histogram counts values into equally wide bins. Counts[i] holds the values between Edges[i] and Edges[i+1].
+type Histogram struct {
+	Edges  []float64 `json:"edges"`
+	Counts []int     `json:"counts"`
+}
+
+// Stats summarises a set of parameters
+type Stats struct {
+	Count     int       `json:"count"`
+	Min       float64   `json:"min"`
+	Max       float64   `json:"max"`
+	Mean      float64   `json:"mean"`
+	Std       float64   `json:"std"`
+	Histogram Histogram `json:"histogram"`
+}
+
+// LayerStats summarises the parameters of a layer. The weights of projection skips are counted with the layer's
+// weights, tied weights only with the layer they belong to, and every parameter of a custom layer is counted as a
+// weight.
+type LayerStats struct {
+	Weights Stats `json:"weights"`
+	Biases  Stats `json:"biases"`
+}
+
+// WeightStats returns statistics of the weights and biases of every layer, with histograms of the given number of
+// bins. Growing extremes or deviations are a sign of parameters exploding.
+func (n Network) WeightStats(bins int) []LayerStats {
+	res := make([]LayerStats, len(n.layers))
+
+	for i, l := range n.layers {
+		params := l.params()
+
+		if l.custom != nil {
+			res[i].Weights = newStats(params, bins)
+			continue
+		}
+
+		if l.biases == nil {
+			res[i].Weights = newStats(params, bins)
+			continue
+		}
+
+		b := 1
+
+		if l.tie != nil {
+			b = 0
+		}
+
+		res[i].Weights = newStats(append(params[:b:b], params[b+1:]...), bins)
+		res[i].Biases = newStats(params[b:b+1], bins)
+	}
+
+	return res
+}
+
+// newStats summarises every value of a set of matrices
+func newStats(ms []mat.Matrix, bins int) Stats {
+	var (
+		s      = Stats{Min: math.Inf(1), Max: math.Inf(-1)}
+		values []float64
+	)
+
+	for _, m := range ms {
+		r, c := m.Dims()
+
+		for y := 0; y < r; y++ {
+			for x := 0; x < c; x++ {
+				values = append(values, m.At(y, x))
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		return Stats{}
+	}
+
+	for _, v := range values {
+		s.Mean += v
+
+		if !math.IsNaN(v) {
+			s.Min = math.Min(s.Min, v)
+			s.Max = math.Max(s.Max, v)
+		}
+	}
+
+	s.Count = len(values)
+	s.Mean /= float64(s.Count)
+
+	for _, v := range values {
+		s.Std += (v - s.Mean) * (v - s.Mean)
+	}
+
+	s.Std = math.Sqrt(s.Std / float64(s.Count))
+
+	// Histograms are left out when there are infinite or only NaN parameters, which already show up in the other
+	// statistics
+	if bins < 1 || math.IsInf(s.Min, 0) || math.IsInf(s.Max, 0) {
+		return s
+	}
+
+	s.Histogram = Histogram{Edges: make([]float64, bins+1), Counts: make([]int, bins)}
+	width := (s.Max - s.Min) / float64(bins)
+
+	for i := range s.Histogram.Edges {
+		s.Histogram.Edges[i] = s.Min + width*float64(i)
+	}
+
+	s.Histogram.Edges[bins] = s.Max
+
+	for _, v := range values {
+		if math.IsNaN(v) {
+			continue
+		}
+
+		b := bins - 1
+
+		if width > 0 {
+			b = int(math.Min(float64(bins-1), math.Floor((v-s.Min)/width)))
+		}
+
+		s.Histogram.Counts[b]++
+	}
+
+	return s
+}
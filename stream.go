@@ -0,0 +1,170 @@
+package nn
+
+import (
+	"sync"
+	"time"
+)
+
+// StreamOptions configures Stream
+type StreamOptions struct {
+	// Workers is the number of goroutines evaluating batches, 1 when zero
+	Workers int
+
+	// BatchSize is the largest number of inputs handed to a worker at once, 1 when zero
+	BatchSize int
+
+	// Window is how long a batch waits for more inputs after its first one before being handed out anyway. Batches
+	// are only handed out when full when it is zero.
+	Window time.Duration
+
+	// Buffer is the capacity of the returned channel
+	Buffer int
+}
+
+// StreamResult is the outcome of evaluating one input sent to Stream
+type StreamResult struct {
+	Input  []float64
+	Output []float64
+
+	// Err is set instead of Output when the input has the wrong size
+	Err error
+}
+
+// streamBatch is a group of inputs evaluated together, numbered in the order they were read
+type streamBatch struct {
+	seq     int
+	inputs  [][]float64
+	results []StreamResult
+}
+
+// Stream evaluates every input received on in and sends the results on the returned channel, in the same order as
+// the inputs. Inputs are collected into batches which are evaluated by a pool of workers. The returned channel is
+// closed once in has been closed and every result has been sent. Like Calc, the network shouldn't be trained while
+// it is being streamed unless it is a copy.
+func (n Network) Stream(in <-chan []float64, opts StreamOptions) <-chan StreamResult {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+
+	if opts.BatchSize < 1 {
+		opts.BatchSize = 1
+	}
+
+	var (
+		out     = make(chan StreamResult, opts.Buffer)
+		batches = make(chan streamBatch, opts.Workers)
+		done    = make(chan streamBatch, opts.Workers)
+		wg      sync.WaitGroup
+	)
+
+	go collectBatches(in, batches, opts)
+
+	for w := 0; w < opts.Workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for b := range batches {
+				b.results = make([]StreamResult, len(b.inputs))
+
+				for i, input := range b.inputs {
+					b.results[i] = n.streamCalc(input)
+				}
+
+				done <- b
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	go func() {
+		defer close(out)
+
+		var (
+			next    = 0
+			pending = map[int]streamBatch{}
+		)
+
+		for b := range done {
+			pending[b.seq] = b
+
+			for {
+				b, ok := pending[next]
+				if !ok {
+					break
+				}
+
+				for _, r := range b.results {
+					out <- r
+				}
+
+				delete(pending, next)
+				next++
+			}
+		}
+	}()
+
+	return out
+}
+
+// collectBatches reads inputs into batches, handing each out when it is full, when its window has passed or when in
+// is closed
+func collectBatches(in <-chan []float64, batches chan<- streamBatch, opts StreamOptions) {
+	defer close(batches)
+
+	var (
+		b      = streamBatch{}
+		timer  *time.Timer
+		expiry <-chan time.Time
+	)
+
+	flush := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, expiry = nil, nil
+		}
+
+		if len(b.inputs) == 0 {
+			return
+		}
+
+		batches <- b
+		b = streamBatch{seq: b.seq + 1}
+	}
+
+	for {
+		select {
+		case input, ok := <-in:
+			if !ok {
+				flush()
+				return
+			}
+
+			b.inputs = append(b.inputs, input)
+
+			if len(b.inputs) >= opts.BatchSize {
+				flush()
+			} else if len(b.inputs) == 1 && opts.Window > 0 {
+				timer = time.NewTimer(opts.Window)
+				expiry = timer.C
+			}
+		case <-expiry:
+			timer, expiry = nil, nil
+			flush()
+		}
+	}
+}
+
+// streamCalc evaluates a single input, reporting a wrong size as an error rather than panicking
+func (n Network) streamCalc(input []float64) StreamResult {
+	if err := n.checkInput(input); err != nil {
+		return StreamResult{Input: input, Err: err}
+	}
+
+	return StreamResult{Input: input, Output: n.Calc(input)}
+}
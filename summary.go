@@ -0,0 +1,72 @@
+package nn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Summary describes the network's layout in a human readable form
+func (n Network) Summary() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Network with %d inputs, %d outputs and %d layers (learning rate %g)\n", n.i, n.o, n.h, n.learnRate)
+
+	for a, size := range n.inputs {
+		fmt.Fprintf(&b, "  [%d] input, size %d\n", a, size)
+	}
+
+	params := 0
+
+	for i, l := range n.layers {
+		a := len(n.inputs) + i
+
+		for _, p := range l.params() {
+			r, c := p.Dims()
+			params += r * c
+		}
+
+		if l.custom != nil {
+			fmt.Fprintf(&b, "  [%d] %s, size %d, from [%d]", a, l.custom.Name(), l.size(), l.from)
+		} else {
+			fmt.Fprintf(&b, "  [%d] dense %s, size %d, from [%d]", a, l.activation.Name, l.size(), l.from)
+
+			if l.biases == nil {
+				b.WriteString(", no bias")
+			}
+
+			if l.tie != nil && l.tie.transpose {
+				fmt.Fprintf(&b, ", transposed weights of [%d]", len(n.inputs)+l.tie.source)
+			} else if l.tie != nil {
+				fmt.Fprintf(&b, ", weights of [%d]", len(n.inputs)+l.tie.source)
+			}
+		}
+
+		for _, s := range l.skips {
+			if s.weights == nil {
+				fmt.Fprintf(&b, ", identity skip from [%d]", s.from)
+				continue
+			}
+
+			fmt.Fprintf(&b, ", projection skip from [%d]", s.from)
+		}
+
+		if l.dropout > 0 {
+			fmt.Fprintf(&b, ", dropout %g", l.dropout)
+		}
+
+		b.WriteString("\n")
+	}
+
+	for i, a := range n.outputs {
+		fmt.Fprintf(&b, "  output %d: [%d], %s loss, weight %g\n", i, a, n.heads[i].loss.Name, n.heads[i].weight)
+	}
+
+	if t := n.Temperature(); t != 1 {
+		fmt.Fprintf(&b, "temperature %g\n", t)
+	}
+
+	fmt.Fprintf(&b, "%d trainable parameters\n", params)
+	fmt.Fprintf(&b, "fingerprint %s\n", n.Fingerprint())
+
+	return b.String()
+}
@@ -0,0 +1,143 @@
+package nn
+
+import (
+	"encoding/binary"
+	"fmt"
+	"google.golang.org/protobuf/encoding/protowire"
+	"hash/crc32"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// crc32c is the checksum used by TFRecord files
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// TensorBoard writes training scalars to a TensorBoard event file, so training runs can be viewed with
+// `tensorboard --logdir`
+type TensorBoard struct {
+	f *os.File
+}
+
+// NewTensorBoard Creates a new event file in dir, creating dir if it doesn't exist
+func NewTensorBoard(dir string) (*TensorBoard, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+
+	f, err := os.Create(filepath.Join(dir, fmt.Sprintf("events.out.tfevents.%d.%s", time.Now().Unix(), host)))
+	if err != nil {
+		return nil, err
+	}
+
+	t := &TensorBoard{f: f}
+
+	event := appendWallTime(nil)
+	event = protowire.AppendTag(event, 3, protowire.BytesType)
+	event = protowire.AppendString(event, "brain.Event:2")
+
+	err = t.record(event)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Scalar writes a single value of a named series
+func (t *TensorBoard) Scalar(tag string, step int, value float64) error {
+	v := protowire.AppendTag(nil, 1, protowire.BytesType)
+	v = protowire.AppendString(v, tag)
+	v = protowire.AppendTag(v, 2, protowire.Fixed32Type)
+	v = protowire.AppendFixed32(v, math.Float32bits(float32(value)))
+
+	summary := protowire.AppendTag(nil, 1, protowire.BytesType)
+	summary = protowire.AppendBytes(summary, v)
+
+	event := appendWallTime(nil)
+	event = protowire.AppendTag(event, 2, protowire.VarintType)
+	event = protowire.AppendVarint(event, uint64(step))
+	event = protowire.AppendTag(event, 5, protowire.BytesType)
+	event = protowire.AppendBytes(event, summary)
+
+	return t.record(event)
+}
+
+// appendWallTime appends the wall_time field of an Event, the current time in seconds
+func appendWallTime(b []byte) []byte {
+	b = protowire.AppendTag(b, 1, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(float64(time.Now().UnixNano())/1e9))
+}
+
+// Callback writes the losses, metrics, learning rate and gradient norms of every epoch. Validation loss is only
+// written when there is validation data.
+func (t *TensorBoard) Callback(_ *Network, e Epoch) error {
+	scalars := map[string]float64{
+		"loss/train": e.Loss,
+		"learn_rate": e.LearnRate,
+		"grad_norm":  e.GradNorm,
+	}
+
+	if e.HasValidation {
+		scalars["loss/validation"] = e.ValLoss
+	}
+
+	for name, value := range e.Metrics {
+		scalars["metric/train/"+name] = value
+	}
+
+	for name, value := range e.ValMetrics {
+		scalars["metric/validation/"+name] = value
+	}
+
+	for i, norm := range e.LayerGradNorms {
+		scalars[fmt.Sprintf("grad_norm/layer_%d", i)] = norm
+	}
+
+	for i := range e.ActivationMeans {
+		scalars[fmt.Sprintf("activation_mean/layer_%d", i)] = e.ActivationMeans[i]
+		scalars[fmt.Sprintf("activation_std/layer_%d", i)] = e.ActivationStds[i]
+	}
+
+	for tag, value := range scalars {
+		if err := t.Scalar(tag, e.Epoch, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close closes the event file
+func (t *TensorBoard) Close() error {
+	return t.f.Close()
+}
+
+// record writes data as a single TFRecord
+func (t *TensorBoard) record(data []byte) error {
+	buf := make([]byte, 12, 16+len(data))
+
+	binary.LittleEndian.PutUint64(buf, uint64(len(data)))
+	binary.LittleEndian.PutUint32(buf[8:], maskedCRC(buf[:8]))
+
+	buf = append(buf, data...)
+	buf = append(buf, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(buf[len(buf)-4:], maskedCRC(data))
+
+	_, err := t.f.Write(buf)
+	return err
+}
+
+// maskedCRC calculates the masked CRC-32C checksum TFRecord uses
+func maskedCRC(data []byte) uint32 {
+	crc := crc32.Checksum(data, crc32c)
+	return (crc>>15 | crc<<17) + 0xa282ead8
+}
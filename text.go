@@ -0,0 +1,197 @@
+package nn
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var (
+	errNoVectorizer      = errors.New("network has no vectorizer")
+	errInvalidVectorizer = errors.New("invalid vectorizer")
+)
+
+// vectorizerAsset is the name a Vectorizer is stored under by Attach
+const vectorizerAsset = "vectorizer.json"
+
+// Tokenize splits text into lower case words, treating every rune that isn't a letter or digit as a separator
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// VectorizerOptions configures NewVectorizer
+type VectorizerOptions struct {
+	// MinCount is the number of documents a word must appear in to be part of the vocabulary
+	MinCount int
+
+	// MaxSize limits the vocabulary to the words found in the most documents, when it isn't zero
+	MaxSize int
+
+	// TFIDF weights word frequencies by their inverse document frequency and scales every vector to unit length,
+	// instead of counting words
+	TFIDF bool
+}
+
+// Vectorizer turns text into network inputs with one value per word of its vocabulary, either the number of times
+// the word appears (bag of words) or its TF-IDF weight. Words outside the vocabulary are ignored.
+type Vectorizer struct {
+	Vocabulary []string `json:"vocabulary"`
+
+	// IDF holds the inverse document frequency of each word, and is only set for TF-IDF vectorizers
+	IDF []float64 `json:"idf,omitempty"`
+
+	index map[string]int
+}
+
+// NewVectorizer builds a vocabulary from a set of documents, ordered by the number of documents each word appears in
+// and then alphabetically
+func NewVectorizer(docs []string, opts VectorizerOptions) *Vectorizer {
+	df := map[string]int{}
+
+	for _, doc := range docs {
+		seen := map[string]bool{}
+
+		for _, word := range Tokenize(doc) {
+			if !seen[word] {
+				seen[word] = true
+				df[word]++
+			}
+		}
+	}
+
+	var words []string
+
+	for word, count := range df {
+		if count >= opts.MinCount {
+			words = append(words, word)
+		}
+	}
+
+	sort.Slice(words, func(i, j int) bool {
+		if df[words[i]] != df[words[j]] {
+			return df[words[i]] > df[words[j]]
+		}
+
+		return words[i] < words[j]
+	})
+
+	if opts.MaxSize > 0 && len(words) > opts.MaxSize {
+		words = words[:opts.MaxSize]
+	}
+
+	v := &Vectorizer{Vocabulary: words}
+	v.index = v.lookup()
+
+	if opts.TFIDF {
+		v.IDF = make([]float64, len(words))
+
+		for i, word := range words {
+			v.IDF[i] = math.Log(float64(1+len(docs))/float64(1+df[word])) + 1
+		}
+	}
+
+	return v
+}
+
+// Size returns the size of the vectors made by the vectorizer, which is the size of its vocabulary
+func (v *Vectorizer) Size() int {
+	return len(v.Vocabulary)
+}
+
+// lookup returns a map from each word of the vocabulary to its position, which is only built once by NewVectorizer
+// and LoadVectorizer
+func (v *Vectorizer) lookup() map[string]int {
+	if v.index != nil {
+		return v.index
+	}
+
+	index := make(map[string]int, len(v.Vocabulary))
+
+	for i, word := range v.Vocabulary {
+		index[word] = i
+	}
+
+	return index
+}
+
+// Vector turns a document into a network input. Vector is safe for concurrent use.
+func (v *Vectorizer) Vector(text string) []float64 {
+	var (
+		res    = make([]float64, len(v.Vocabulary))
+		tokens = Tokenize(text)
+		index  = v.lookup()
+	)
+
+	for _, word := range tokens {
+		if i, ok := index[word]; ok {
+			res[i]++
+		}
+	}
+
+	if v.IDF == nil || len(tokens) == 0 {
+		return res
+	}
+
+	norm := 0.0
+
+	for i := range res {
+		res[i] *= v.IDF[i] / float64(len(tokens))
+		norm += res[i] * res[i]
+	}
+
+	if norm > 0 {
+		for i := range res {
+			res[i] /= math.Sqrt(norm)
+		}
+	}
+
+	return res
+}
+
+// Vectors turns every document into a network input
+func (v *Vectorizer) Vectors(docs []string) [][]float64 {
+	res := make([][]float64, len(docs))
+
+	for i, doc := range docs {
+		res[i] = v.Vector(doc)
+	}
+
+	return res
+}
+
+// Attach stores the vectorizer as an asset of the network, so that it is saved and loaded along with it
+func (v *Vectorizer) Attach(n *Network) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return n.SetAsset(vectorizerAsset, data)
+}
+
+// LoadVectorizer returns the vectorizer stored in a network by Attach
+func LoadVectorizer(n Network) (*Vectorizer, error) {
+	data, ok := n.Asset(vectorizerAsset)
+	if !ok {
+		return nil, errNoVectorizer
+	}
+
+	v := &Vectorizer{}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return nil, err
+	}
+
+	if v.IDF != nil && len(v.IDF) != len(v.Vocabulary) {
+		return nil, errInvalidVectorizer
+	}
+
+	v.index = v.lookup()
+
+	return v, nil
+}
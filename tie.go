@@ -0,0 +1,89 @@
+package nn
+
+import (
+	"errors"
+	"gonum.org/v1/gonum/mat"
+)
+
+var (
+	errInvalidTie = errors.New("invalid weight tie")
+)
+
+// TieOptions is for exporting weight ties to JSON
+type TieOptions struct {
+	Layer, Source int
+	Transpose     bool
+}
+
+// weightTie makes a layer use the weights of an earlier layer in place of its own
+type weightTie struct {
+	source    int
+	transpose bool
+	size      int
+}
+
+// TieWeights makes a dense layer use the weights of an earlier dense layer, or their transpose, in place of its own,
+// as the decoder of an autoencoder often shares the weights of its encoder. Layers are numbered from 0. The layer's
+// own weights are dropped, and the gradients of both layers are accumulated into the shared matrix. The shared weights
+// must have the shape the layer's weights had, and a layer can't be tied to a layer which is itself tied.
+func (n *Network) TieWeights(layer, source int, transpose bool) error {
+	if source < 0 || layer <= source || layer >= n.h {
+		return errInvalidTie
+	}
+
+	l, s := &n.layers[layer], n.layers[source]
+
+	if l.custom != nil || s.custom != nil || l.tie != nil || s.tie != nil {
+		return errInvalidTie
+	}
+
+	for _, other := range n.layers {
+		if other.tie != nil && other.tie.source == layer {
+			return errInvalidTie
+		}
+	}
+
+	r, c := l.weights.Dims()
+	sr, sc := s.weights.Dims()
+
+	if transpose {
+		sr, sc = sc, sr
+	}
+
+	if r != sr || c != sc {
+		return errInvalidTie
+	}
+
+	l.weights = nil
+	l.tie = &weightTie{source: source, transpose: transpose, size: r}
+
+	return nil
+}
+
+// Ties returns every weight tie in the network
+func (n Network) Ties() []TieOptions {
+	var ties []TieOptions
+
+	for i, l := range n.layers {
+		if l.tie != nil {
+			ties = append(ties, TieOptions{Layer: i, Source: l.tie.source, Transpose: l.tie.transpose})
+		}
+	}
+
+	return ties
+}
+
+// weights returns the weights used by layer i, which are those of another layer if it is tied
+func (n Network) weights(i int) mat.Matrix {
+	t := n.layers[i].tie
+
+	if t == nil {
+		return n.layers[i].weights
+	}
+
+	if t.transpose {
+		return n.layers[t.source].weights.T()
+	}
+
+	return n.layers[t.source].weights
+}
@@ -0,0 +1,390 @@
+package nn
+
+import (
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+	"time"
+)
+
+// TrainOptions configures TrainWith
+type TrainOptions struct {
+	// Epochs is the number of passes to make over the training data
+	Epochs int
+
+	// ValInputs and ValExpected are a validation set, evaluated after every epoch when given
+	ValInputs, ValExpected [][]float64
+
+	// Optimizer applies the gradients of every sample, SGD when nil
+	Optimizer Optimizer
+
+	// Workers trains with that many goroutines updating the weights without locking (Hogwild) when more than one.
+	// This only works with plain SGD, and not with a NaN policy, EMA, weight decay, gradient noise, adversarial
+	// samples, input corruption or EWC.
+	Workers int
+
+	// WeightDecay adds L2 regularisation, pulling every parameter towards zero by adding WeightDecay times it to its
+	// gradient. NoBiasDecay leaves out biases and other parameters with a single column, such as the scales and shifts
+	// of normalisation layers, which usually works better.
+	WeightDecay float64
+	NoBiasDecay bool
+
+	// GradientNoise adds Gaussian noise to the gradients of every step, with a variance of GradientNoise/(1+t)^γ at
+	// step t, which helps small networks escape poor local minima. γ is GradientNoiseDecay, or 0.55 when that is zero.
+	GradientNoise      float64
+	GradientNoiseDecay float64
+
+	// AdversarialEpsilon, when it isn't zero, also trains on an adversarial copy of samples made from the current
+	// network with FGSM, or IterativeFGSM taking AdversarialSteps steps of AdversarialEpsilon/AdversarialSteps when
+	// that is more than one. AdversarialFraction is the fraction of samples that get a copy, every sample when zero.
+	AdversarialEpsilon  float64
+	AdversarialSteps    int
+	AdversarialFraction float64
+
+	// InputMasking and InputNoise corrupt the inputs the network is trained on, leaving the expected outputs alone.
+	// Each input is zeroed with probability InputMasking, and Gaussian noise with a standard deviation of InputNoise
+	// is added to the rest. Training an Autoencoder this way makes a denoising autoencoder, which learns to reconstruct
+	// clean inputs from corrupted ones. Losses are still measured on the clean inputs.
+	InputMasking float64
+	InputNoise   float64
+
+	// NaNPolicy decides what happens when a gradient or weight becomes NaN or infinite
+	NaNPolicy NaNPolicy
+
+	// EMA, when set, is updated with the network's parameters after every step
+	EMA *EMA
+
+	// EWC, when set, penalises moving the parameters that mattered to the tasks it has consolidated
+	EWC *EWC
+
+	// ActivationStats records the mean and standard deviation of every layer's activations during training in every
+	// that many epochs, starting with the first, when it isn't zero
+	ActivationStats int
+
+	// QuantizationAware simulates int8 quantization of the weights and activations of every layer in the forward pass
+	// during training, passing gradients straight through the rounding, so the network learns weights that lose less
+	// accuracy when it is quantized afterwards. See Quantized.
+	QuantizationAware bool
+
+	// Sampler chooses which samples are trained on in each epoch and in what order, every sample in order when nil
+	Sampler Sampler
+
+	// Metrics are evaluated on the training and validation sets after every epoch and recorded in the epoch's Metrics
+	// and ValMetrics under their names. Each function makes a fresh Metric, such as
+	// func() Metric { return &TopKAccuracy{K: 3} }.
+	Metrics map[string]func() Metric
+
+	// Callbacks are called in order after every epoch. If one returns an error training stops and TrainWith returns
+	// that error.
+	Callbacks []Callback
+
+	// Quiet stops progress being printed
+	Quiet bool
+}
+
+// Epoch describes a finished epoch of training
+type Epoch struct {
+	// Epoch counts from 1
+	Epoch int `json:"epoch"`
+
+	// Loss is the average cost over the training data, and ValLoss over the validation data if HasValidation is set
+	Loss    float64 `json:"loss"`
+	ValLoss float64 `json:"val_loss,omitempty"`
+
+	// HasValidation is set when TrainOptions had validation data, so a ValLoss of 0 is a perfect fit rather than
+	// no validation at all
+	HasValidation bool `json:"has_validation,omitempty"`
+
+	// Metrics and ValMetrics hold the results of TrainOptions.Metrics over the training and validation data
+	Metrics    map[string]float64 `json:"metrics,omitempty"`
+	ValMetrics map[string]float64 `json:"val_metrics,omitempty"`
+
+	// LearnRate is the learning rate used during the epoch
+	LearnRate float64 `json:"learn_rate"`
+
+	// GradNorm is the average norm of the gradients applied during the epoch, and LayerGradNorms the average norm of
+	// the gradients of each layer
+	GradNorm       float64   `json:"grad_norm"`
+	LayerGradNorms []float64 `json:"layer_grad_norms"`
+
+	// ActivationMeans and ActivationStds are the mean and standard deviation of the activations of each layer during
+	// the epoch, when TrainOptions.ActivationStats asks for them
+	ActivationMeans []float64 `json:"activation_means,omitempty"`
+	ActivationStds  []float64 `json:"activation_stds,omitempty"`
+
+	// Samples is the number of training samples seen during the epoch, and Skipped the number of them whose update
+	// was left out because of a NaN or infinite value
+	Samples int `json:"samples"`
+	Skipped int `json:"skipped,omitempty"`
+
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Callback is called by TrainWith after every epoch
+type Callback func(n *Network, e Epoch) error
+
+// Cost returns the average cost of the network over a set of samples
+func (n Network) Cost(inputs, expected [][]float64) float64 {
+	if len(inputs) != len(expected) {
+		panic(fmt.Errorf("%w: %d inputs but %d expected outputs", ErrDimensionMismatch, len(inputs), len(expected)))
+	}
+
+	total := 0.0
+
+	for i := range inputs {
+		total += n.cost(n.Calc(inputs[i]), expected[i])
+	}
+
+	return total / float64(len(inputs))
+}
+
+// TrainWith repeatedly performs backpropagation as configured by opts, returning a report of the run. If a callback
+// stops training the report covers the epochs that were completed.
+func (n *Network) TrainWith(inputs, expected [][]float64, opts TrainOptions) (Report, error) {
+	// Every sample is checked before training starts, so a malformed one can't leave the network half trained
+	if err := n.checkSamples(inputs, expected); err != nil {
+		return Report{}, err
+	}
+
+	if err := n.checkSamples(opts.ValInputs, opts.ValExpected); err != nil {
+		return Report{}, fmt.Errorf("validation %w", err)
+	}
+
+	if opts.InputMasking < 0 || opts.InputMasking >= 1 || opts.InputNoise < 0 {
+		return Report{}, errInvalidCorruption
+	}
+
+	if opts.EWC != nil && !opts.EWC.fits(*n) {
+		return Report{}, fmt.Errorf("%w: the network doesn't match the tasks EWC has consolidated",
+			ErrIncompatibleArchitectures)
+	}
+
+	if !opts.Quiet {
+		fmt.Printf("Began training for %d epochs...\n", opts.Epochs)
+	}
+
+	var (
+		start  = time.Now()
+		report = n.newReport(inputs, opts)
+		opt    = opts.Optimizer
+	)
+
+	if opt == nil {
+		opt = SGD{}
+	}
+
+	if opts.Workers > 1 {
+		if _, ok := opt.(SGD); !ok || opts.NaNPolicy != NaNIgnore || opts.EMA != nil || opts.WeightDecay != 0 ||
+			opts.GradientNoise != 0 || opts.AdversarialEpsilon != 0 || opts.corrupts() ||
+			opts.EWC != nil {
+			return Report{}, errHogwildOptions
+		}
+
+		n.ownParams()
+	}
+
+	if opts.EWC != nil {
+		opt = ewcPenalty{Optimizer: opt, ewc: opts.EWC}
+	}
+
+	if opts.WeightDecay != 0 {
+		opt = weightDecay{Optimizer: opt, decay: opts.WeightDecay, biases: !opts.NoBiasDecay}
+	}
+
+	if opts.GradientNoise != 0 {
+		gamma := opts.GradientNoiseDecay
+
+		if gamma == 0 {
+			gamma = defaultGradientNoiseDecay
+		}
+
+		opt = &gradientNoise{Optimizer: opt, eta: opts.GradientNoise, gamma: gamma}
+	}
+
+	if opts.QuantizationAware && !n.quantize {
+		n.quantize = true
+
+		defer func() {
+			n.quantize = false
+		}()
+	}
+
+	for epoch := 0; epoch < opts.Epochs; epoch++ {
+		counter := time.Now()
+		order, err := sampleOrder(opts.Sampler, epoch+1, inputs, expected)
+		if err != nil {
+			return report, err
+		}
+
+		e := Epoch{Epoch: epoch + 1, LearnRate: n.learnRate, Samples: len(order), LayerGradNorms: make([]float64, n.h)}
+
+		var (
+			losses      []float64
+			recorder, _ = opts.Sampler.(LossRecorder)
+		)
+
+		if recorder != nil {
+			losses = make([]float64, len(inputs))
+
+			for i := range losses {
+				losses[i] = math.NaN()
+			}
+		}
+
+		var stats *activationStats
+
+		if opts.ActivationStats > 0 && epoch%opts.ActivationStats == 0 {
+			stats = n.recordActivations()
+		}
+
+		if opts.Workers > 1 {
+			n.hogwildEpoch(inputs, expected, order, losses, opts.Workers, &e)
+		} else {
+			err = n.serialEpoch(inputs, expected, order, losses, opt, opts, &e)
+		}
+
+		if stats != nil {
+			e.ActivationMeans, e.ActivationStds = stats.stop(n)
+		}
+
+		if err != nil {
+			return report, err
+		}
+
+		if recorder != nil {
+			recorder.RecordLosses(epoch+1, losses)
+		}
+
+		if len(order) > 0 {
+			e.Loss /= float64(len(order))
+			e.GradNorm /= float64(len(order))
+		}
+
+		if len(opts.ValInputs) > 0 {
+			e.ValLoss, e.HasValidation = n.Cost(opts.ValInputs, opts.ValExpected), true
+		}
+
+		e.Metrics = n.evaluateMetrics(opts.Metrics, inputs, expected)
+		e.ValMetrics = n.evaluateMetrics(opts.Metrics, opts.ValInputs, opts.ValExpected)
+
+		e.Duration = time.Since(counter)
+		report.Epochs = append(report.Epochs, e)
+
+		if !opts.Quiet {
+			e.print(opts)
+		}
+
+		for _, cb := range opts.Callbacks {
+			if err := cb(n, e); err != nil {
+				report.finish(n, inputs, expected, opts, start)
+				report.Error = err.Error()
+				return report, err
+			}
+		}
+	}
+
+	report.finish(n, inputs, expected, opts, start)
+
+	if !opts.Quiet && opts.Epochs > 0 {
+		delta := time.Since(start).Milliseconds()
+
+		fmt.Printf("Trained for %d epochs in %dms with an average of %dms per epoch.\n",
+			opts.Epochs, delta, delta/int64(opts.Epochs))
+	}
+
+	return report, nil
+}
+
+// serialEpoch trains on the samples in order, one at a time, recording the loss of each in losses if it isn't nil
+func (n *Network) serialEpoch(inputs, expected [][]float64, order []int, losses []float64, opt Optimizer,
+	opts TrainOptions, e *Epoch) error {
+	var checkpoint [][]*mat.Dense
+
+	if opts.NaNPolicy == NaNRollback {
+		checkpoint = n.copyParams()
+	}
+
+	for _, i := range order {
+		grads, ok, err := n.guardedStep(opt, opts.corrupt(inputs[i]), expected[i], opts.NaNPolicy, checkpoint)
+		if err != nil {
+			return fmt.Errorf("sample %d of epoch %d: %w", i, e.Epoch, err)
+		}
+
+		if !ok {
+			e.Skipped++
+			continue
+		}
+
+		if opts.EMA != nil {
+			if err = opts.EMA.Update(n); err != nil {
+				return err
+			}
+		}
+
+		e.GradNorm += grads.Norm()
+
+		for l, norm := range grads.LayerNorms() {
+			e.LayerGradNorms[l] += norm / float64(len(order))
+		}
+
+		loss := n.cost(n.Calc(inputs[i]), expected[i])
+		e.Loss += loss
+
+		if losses != nil {
+			losses[i] = loss
+		}
+
+		if !opts.adversarial() {
+			continue
+		}
+
+		adv := n.adversarialSample(inputs[i], expected[i], opts)
+
+		if _, ok, err = n.guardedStep(opt, adv, expected[i], opts.NaNPolicy, checkpoint); err != nil {
+			return fmt.Errorf("adversarial copy of sample %d of epoch %d: %w", i, e.Epoch, err)
+		}
+
+		if !ok {
+			e.Skipped++
+		} else if opts.EMA != nil {
+			if err = opts.EMA.Update(n); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// WarnGradients returns a callback which prints a warning whenever the average gradient norm of a layer falls below
+// vanishing or rises above exploding. Deep stacks of sigmoid layers often stop learning because their gradients
+// vanish, which otherwise goes unnoticed. Either check is skipped when its threshold is zero.
+func WarnGradients(vanishing, exploding float64) Callback {
+	return func(n *Network, e Epoch) error {
+		for i, norm := range e.LayerGradNorms {
+			if vanishing > 0 && norm < vanishing {
+				fmt.Printf("  ! Gradients of layer %d are vanishing in epoch %d, with an average norm of %.3g,\n",
+					i, e.Epoch, norm)
+			}
+
+			if exploding > 0 && norm > exploding {
+				fmt.Printf("  ! Gradients of layer %d are exploding in epoch %d, with an average norm of %.3g,\n",
+					i, e.Epoch, norm)
+			}
+		}
+
+		return nil
+	}
+}
+
+// print reports the progress of an epoch
+func (e Epoch) print(opts TrainOptions) {
+	if len(opts.ValInputs) > 0 {
+		fmt.Printf("  + Completed epoch %d of %d in %dms with an average cost of %.5f and validation cost of %.5f,\n",
+			e.Epoch, opts.Epochs, e.Duration.Milliseconds(), e.Loss, e.ValLoss)
+		return
+	}
+
+	fmt.Printf("  + Completed epoch %d of %d in %dms with an average cost of %.5f,\n",
+		e.Epoch, opts.Epochs, e.Duration.Milliseconds(), e.Loss)
+}
@@ -0,0 +1,137 @@
+package nn
+
+import (
+	"errors"
+	"fmt"
+	"gonum.org/v1/gonum/mat"
+	"math"
+)
+
+var (
+	errNoTriplets = errors.New("no triplets to learn from")
+)
+
+// Triplet is a training example for an embedding: an anchor, an input similar to it and an input that isn't
+type Triplet struct {
+	Anchor, Positive, Negative []float64
+}
+
+// TripletLoss returns max(0, d(anchor, positive) - d(anchor, negative) + margin), where d is the squared euclidean
+// distance between embeddings. It is zero once the negative is at least margin further from the anchor than the
+// positive.
+func TripletLoss(anchor, positive, negative []float64, margin float64) float64 {
+	return math.Max(0, squaredDistance(anchor, positive)-squaredDistance(anchor, negative)+margin)
+}
+
+// squaredDistance returns the squared euclidean distance between two vectors of the same length
+func squaredDistance(a, b []float64) float64 {
+	d := 0.0
+
+	for i := range a {
+		d += (a[i] - b[i]) * (a[i] - b[i])
+	}
+
+	return d
+}
+
+// TripletTrainer trains a network to embed its inputs so that similar inputs end up close together, by running the
+// three inputs of every triplet through the same network and minimising their TripletLoss. The network's outputs are
+// the embedding, so they usually should be Linear.
+type TripletTrainer struct {
+	// Margin is how much further negatives should be from the anchor than positives
+	Margin float64
+
+	// Optimizer applies the gradients of every triplet, SGD when nil
+	Optimizer Optimizer
+}
+
+// Train trains the network on every triplet in order for a number of epochs, returning the average loss of each epoch
+func (t TripletTrainer) Train(n *Network, triplets []Triplet, epochs int) ([]float64, error) {
+	if len(triplets) == 0 {
+		return nil, errNoTriplets
+	}
+
+	for i, tr := range triplets {
+		for _, input := range [][]float64{tr.Anchor, tr.Positive, tr.Negative} {
+			if err := n.checkInput(input); err != nil {
+				return nil, fmt.Errorf("triplet %d: %w", i, err)
+			}
+		}
+	}
+
+	opt := t.Optimizer
+	if opt == nil {
+		opt = SGD{}
+	}
+
+	losses := make([]float64, epochs)
+
+	for epoch := range losses {
+		for _, tr := range triplets {
+			grads, loss := n.tripletGradients(tr, t.Margin)
+			losses[epoch] += loss
+
+			if grads != nil {
+				opt.Step(n, grads, n.learnRate)
+			}
+		}
+
+		losses[epoch] /= float64(len(triplets))
+	}
+
+	return losses, nil
+}
+
+// tripletGradients returns the gradients of the TripletLoss of a triplet and the loss itself. The gradients are nil
+// when the loss is zero.
+func (n Network) tripletGradients(t Triplet, margin float64) (Gradients, float64) {
+	ea, ep, en := n.embed(t.Anchor), n.embed(t.Positive), n.embed(t.Negative)
+	a, p, neg := ea.values, ep.values, en.values
+
+	loss := TripletLoss(a, p, neg, margin)
+	if loss == 0 {
+		return nil, 0
+	}
+
+	ga, gp, gn := make([]float64, len(a)), make([]float64, len(a)), make([]float64, len(a))
+
+	for i := range a {
+		ga[i] = 2 * (neg[i] - p[i])
+		gp[i] = 2 * (p[i] - a[i])
+		gn[i] = 2 * (a[i] - neg[i])
+	}
+
+	grads := n.embeddingGradients(ea, ga)
+	grads = grads.Add(n.embeddingGradients(ep, gp))
+	grads = grads.Add(n.embeddingGradients(en, gn))
+
+	return grads, loss
+}
+
+// embedding is a forward pass over a single input with dropout as in training, kept so that a loss computed from its
+// values can be backpropagated through the same pass
+type embedding struct {
+	zs, activations, masks []mat.Matrix
+
+	// values are the concatenated outputs of the pass
+	values []float64
+}
+
+// embed runs a forward pass for a single input, with dropout as in training
+func (n Network) embed(input []float64) embedding {
+	zs, activations, masks := n.forwardDropout(split(input, n.inputs), true)
+	return embedding{zs: zs, activations: activations, masks: masks, values: n.outputValues(activations)}
+}
+
+// embeddingGradients runs the backward pass of an embedding and returns the gradients of a cost whose gradient with
+// respect to its values is grad
+func (n Network) embeddingGradients(e embedding, grad []float64) Gradients {
+	grads, _ := n.backward(e.zs, e.activations, e.masks, split(grad, n.outputSizes()))
+	return grads
+}
+
+// outputGradients runs a forward and backward pass for a single input, with dropout as in training, and returns the
+// gradients of a cost whose gradient with respect to the concatenated outputs is grad
+func (n Network) outputGradients(input, grad []float64) Gradients {
+	return n.embeddingGradients(n.embed(input), grad)
+}
@@ -1,30 +1,154 @@
 package nn
 
 import (
-	"errors"
+	"fmt"
 	"gonum.org/v1/gonum/mat"
 	"math"
 	"math/rand"
+	"runtime"
+	"sync"
 	"time"
 )
 
-var (
-	errInvalidDataSize = errors.New("invalid data size")
-)
+// DimensionError describes data of the wrong size given to a network, naming which argument was wrong. It wraps
+// ErrDimensionMismatch, and is what methods that can't return an error panic with.
+type DimensionError struct {
+	// Arg is the argument that was wrong, such as "input" or "expected output", and Unit what its elements are
+	Arg, Unit string
+
+	Got, Want int
+}
+
+// Error describes the mismatch, such as "input has 784 features, network expects 64"
+func (e DimensionError) Error() string {
+	return fmt.Sprintf("%s has %d %s, network expects %d", e.Arg, e.Got, e.Unit, e.Want)
+}
+
+// Unwrap returns ErrDimensionMismatch, so errors.Is matches it
+func (e DimensionError) Unwrap() error {
+	return ErrDimensionMismatch
+}
+
+// checkInput returns a DimensionError if data isn't the size of the network's input
+func (n Network) checkInput(data []float64) error {
+	if len(data) != n.i {
+		return DimensionError{Arg: "input", Unit: "features", Got: len(data), Want: n.i}
+	}
+
+	return nil
+}
+
+// checkSample returns a DimensionError if input or expected aren't the size of the network's input or output
+func (n Network) checkSample(input, expected []float64) error {
+	if err := n.checkInput(input); err != nil {
+		return err
+	}
+
+	if len(expected) != n.o {
+		return DimensionError{Arg: "expected output", Unit: "values", Got: len(expected), Want: n.o}
+	}
+
+	return nil
+}
+
+// checkSamples returns an error if there aren't as many inputs as expected outputs, or a DimensionError naming the
+// index of the first sample of the wrong size
+func (n Network) checkSamples(inputs, expected [][]float64) error {
+	if len(inputs) != len(expected) {
+		return fmt.Errorf("%w: %d inputs but %d expected outputs", ErrDimensionMismatch, len(inputs), len(expected))
+	}
+
+	for i := range inputs {
+		if err := n.checkSample(inputs[i], expected[i]); err != nil {
+			return fmt.Errorf("sample %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// parallel runs jobs on as many goroutines as there are CPUs to use, returning the first error any of them return
+func parallel(jobs []func() error) error {
+	var (
+		next    = make(chan func() error)
+		errs    = make(chan error, len(jobs))
+		workers = runtime.GOMAXPROCS(0)
+		wg      sync.WaitGroup
+	)
+
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for job := range next {
+				if err := job(); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+
+	for _, job := range jobs {
+		next <- job
+	}
+
+	close(next)
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
 
 // lerp is used to map random numbers across a range
 func lerp(x, li, ui, lo, uo float64) float64 {
 	return ((x-li)/(ui-li))*(uo-lo) + lo
 }
 
-// sigmoid is the network's activation function
+// sigmoid is the network's activation function. math.Exp is only ever given a value of at most zero, so it can't
+// overflow however large the input is.
 func sigmoid(_, _ int, v float64) float64 {
-	return 1 / (1 + math.Exp(-v))
+	if v >= 0 {
+		return 1 / (1 + math.Exp(-v))
+	}
+
+	e := math.Exp(v)
+	return e / (1 + e)
 }
 
-// dSigmoid is the derivative of the network's activation function
+// softplus is log(1+e^v), rearranged so math.Exp is only ever given a value of at most zero
+func softplus(_, _ int, v float64) float64 {
+	if v > 0 {
+		return v + math.Log1p(math.Exp(-v))
+	}
+
+	return math.Log1p(math.Exp(v))
+}
+
+// dSigmoid is the derivative of the network's activation function. It uses 1 - sigmoid(v) = sigmoid(-v), which
+// stays accurate for large inputs where 1 - sigmoid(v) would round to zero.
 func dSigmoid(_, _ int, v float64) float64 {
-	return sigmoid(0, 0, v) * (1 - sigmoid(0, 0, v))
+	return sigmoid(0, 0, v) * sigmoid(0, 0, -v)
+}
+
+// dSigmoidOutput is the derivative of the sigmoid given its output rather than its input
+func dSigmoidOutput(_, _ int, v float64) float64 {
+	return v * (1 - v)
+}
+
+// tanh is the activation function used inside LSTM cells
+func tanh(_, _ int, v float64) float64 {
+	return math.Tanh(v)
+}
+
+// dTanhOutput is the derivative of tanh given its output rather than its input
+func dTanhOutput(_, _ int, v float64) float64 {
+	return 1 - v*v
 }
 
 // Produces a random array for initialising the weights and biases
@@ -93,7 +217,7 @@ func sub(m, n mat.Matrix) mat.Matrix {
 // totalCost calculates the sum of all the costs
 func totalCost(got, expected []float64) float64 {
 	if len(got) != len(expected) {
-		panic(errInvalidDataSize)
+		panic(ErrDimensionMismatch)
 	}
 
 	total := 0.0
@@ -104,3 +228,26 @@ func totalCost(got, expected []float64) float64 {
 
 	return total
 }
+
+// softmax turns a vector of scores into probabilities which sum to one
+func softmax(v []float64) []float64 {
+	res := make([]float64, len(v))
+	largest := math.Inf(-1)
+
+	for _, x := range v {
+		largest = math.Max(largest, x)
+	}
+
+	total := 0.0
+
+	for i, x := range v {
+		res[i] = math.Exp(x - largest)
+		total += res[i]
+	}
+
+	for i := range res {
+		res[i] /= total
+	}
+
+	return res
+}